@@ -0,0 +1,605 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/**
+	This is a persistent B-tree Map implementation for large maps
+
+	Put and Remove copy only the path from the root to the leaf, O(log n), other nodes are shared
+	between versions. Iteration, Pack and PrintJSON go in the key order, so the serialization and
+	Hash are identical to sortedMapValue with the same entries.
+
+	Values of duplicate keys are kept together in the node in the same order as in sortedMapValue.
+*/
+
+const btreeMinDegree = 16
+const btreeMaxItems = 2*btreeMinDegree - 1
+
+type btreeItem struct {
+	key    string
+	values []Value
+}
+
+type btreeNode struct {
+	items    []btreeItem
+	children []*btreeNode // nil for leaf
+}
+
+type btreeMapValue struct {
+	root *btreeNode
+	size int
+}
+
+var btreeMapValueClass = reflect.TypeOf((*btreeMapValue)(nil)).Elem()
+
+var emptyBTreeMap = btreeMapValue{}
+
+func EmptyBTreeMap() Map {
+	return emptyBTreeMap
+}
+
+/**
+	Creates B-tree Map from entries in any order, duplicate keys keep the order of entries
+*/
+
+func BTreeMap(entries []MapEntry) Map {
+	t := emptyBTreeMap
+	for _, entry := range entries {
+		old, _ := t.lookup(entry.Key())
+		values := make([]Value, len(old), len(old)+1)
+		copy(values, old)
+		t = t.set(entry.Key(), old, append(values, entry.Value()))
+	}
+	return t
+}
+
+func (t btreeMapValue) lookup(key string) ([]Value, bool) {
+	n := t.root
+	for n != nil {
+		i, found := n.search(key)
+		if found {
+			return n.items[i].values, true
+		}
+		if n.children == nil {
+			break
+		}
+		n = n.children[i]
+	}
+	return nil, false
+}
+
+/**
+	Replaces values of the key, empty values remove the key
+*/
+
+func (t btreeMapValue) set(key string, old, values []Value) btreeMapValue {
+	size := t.size - len(old) + len(values)
+	if len(values) == 0 {
+		if len(old) == 0 {
+			return t
+		}
+		root := t.root.clone()
+		root.remove(key)
+		if len(root.items) == 0 {
+			if root.children == nil {
+				root = nil
+			} else {
+				root = root.children[0]
+			}
+		}
+		return btreeMapValue{root, size}
+	}
+	item := btreeItem{key, values}
+	if t.root == nil {
+		return btreeMapValue{&btreeNode{items: []btreeItem{item}}, size}
+	}
+	var root *btreeNode
+	if len(t.root.items) >= btreeMaxItems {
+		left, mid, right := t.root.split()
+		root = &btreeNode{items: []btreeItem{mid}, children: []*btreeNode{left, right}}
+	} else {
+		root = t.root.clone()
+	}
+	root.insert(item)
+	return btreeMapValue{root, size}
+}
+
+func (n *btreeNode) search(key string) (int, bool) {
+	i := sort.Search(len(n.items), func(i int) bool {
+		return n.items[i].key >= key
+	})
+	return i, i < len(n.items) && n.items[i].key == key
+}
+
+func (n *btreeNode) clone() *btreeNode {
+	c := &btreeNode{items: make([]btreeItem, len(n.items), len(n.items)+1)}
+	copy(c.items, n.items)
+	if n.children != nil {
+		c.children = make([]*btreeNode, len(n.children), len(n.children)+1)
+		copy(c.children, n.children)
+	}
+	return c
+}
+
+/**
+	Splits the full node to the new left and right nodes around the median item, the node stays untouched
+*/
+
+func (n *btreeNode) split() (*btreeNode, btreeItem, *btreeNode) {
+	m := btreeMinDegree - 1
+	left := &btreeNode{items: append([]btreeItem(nil), n.items[:m]...)}
+	right := &btreeNode{items: append([]btreeItem(nil), n.items[m+1:]...)}
+	if n.children != nil {
+		left.children = append([]*btreeNode(nil), n.children[:m+1]...)
+		right.children = append([]*btreeNode(nil), n.children[m+1:]...)
+	}
+	return left, n.items[m], right
+}
+
+/**
+	Inserts or replaces the item, the node must be a private copy and not full
+*/
+
+func (n *btreeNode) insert(item btreeItem) {
+	i, found := n.search(item.key)
+	if found {
+		n.items[i] = item
+		return
+	}
+	if n.children == nil {
+		n.insertItemAt(i, item)
+		return
+	}
+	child := n.children[i]
+	if len(child.items) >= btreeMaxItems {
+		left, mid, right := child.split()
+		n.insertItemAt(i, mid)
+		n.children[i] = left
+		n.insertChildAt(i+1, right)
+		switch {
+		case item.key == mid.key:
+			n.items[i] = item
+			return
+		case item.key > mid.key:
+			i++
+		}
+		// left and right are new nodes
+		n.children[i].insert(item)
+		return
+	}
+	child = child.clone()
+	n.children[i] = child
+	child.insert(item)
+}
+
+/**
+	Removes the key, the node must be a private copy and have more than the minimum number of items unless it is the root
+*/
+
+func (n *btreeNode) remove(key string) {
+	i, found := n.search(key)
+	if n.children == nil {
+		if found {
+			n.removeItemAt(i)
+		}
+		return
+	}
+	if !found {
+		n.ensureChild(i, key).remove(key)
+		return
+	}
+	switch {
+	case len(n.children[i].items) >= btreeMinDegree:
+		child := n.children[i].clone()
+		n.children[i] = child
+		pred := child.last()
+		n.items[i] = pred
+		child.remove(pred.key)
+	case len(n.children[i+1].items) >= btreeMinDegree:
+		child := n.children[i+1].clone()
+		n.children[i+1] = child
+		succ := child.first()
+		n.items[i] = succ
+		child.remove(succ.key)
+	default:
+		n.mergeChildren(i).remove(key)
+	}
+}
+
+/**
+	Makes the private copy of the child on the way to the key with more than the minimum number of items
+*/
+
+func (n *btreeNode) ensureChild(i int, key string) *btreeNode {
+	child := n.children[i].clone()
+	n.children[i] = child
+	if len(child.items) >= btreeMinDegree {
+		return child
+	}
+	if i > 0 && len(n.children[i-1].items) >= btreeMinDegree {
+		left := n.children[i-1].clone()
+		n.children[i-1] = left
+		last := len(left.items) - 1
+		child.insertItemAt(0, n.items[i-1])
+		n.items[i-1] = left.items[last]
+		left.removeItemAt(last)
+		if child.children != nil {
+			child.insertChildAt(0, left.children[last+1])
+			left.children = left.children[:last+1]
+		}
+		return child
+	}
+	if i < len(n.items) && len(n.children[i+1].items) >= btreeMinDegree {
+		right := n.children[i+1].clone()
+		n.children[i+1] = right
+		child.items = append(child.items, n.items[i])
+		n.items[i] = right.items[0]
+		right.removeItemAt(0)
+		if child.children != nil {
+			child.children = append(child.children, right.children[0])
+			right.children = right.children[1:]
+		}
+		return child
+	}
+	if i < len(n.items) {
+		return n.mergeChildren(i)
+	}
+	return n.mergeChildren(i - 1)
+}
+
+/**
+	Merges children i and i+1 with the item i between them to the new child i
+*/
+
+func (n *btreeNode) mergeChildren(i int) *btreeNode {
+	left, right := n.children[i], n.children[i+1]
+	merged := &btreeNode{items: make([]btreeItem, 0, len(left.items)+len(right.items)+1)}
+	merged.items = append(merged.items, left.items...)
+	merged.items = append(merged.items, n.items[i])
+	merged.items = append(merged.items, right.items...)
+	if left.children != nil {
+		merged.children = make([]*btreeNode, 0, len(left.children)+len(right.children))
+		merged.children = append(merged.children, left.children...)
+		merged.children = append(merged.children, right.children...)
+	}
+	n.removeItemAt(i)
+	copy(n.children[i+1:], n.children[i+2:])
+	n.children[len(n.children)-1] = nil
+	n.children = n.children[:len(n.children)-1]
+	n.children[i] = merged
+	return merged
+}
+
+func (n *btreeNode) first() btreeItem {
+	for n.children != nil {
+		n = n.children[0]
+	}
+	return n.items[0]
+}
+
+func (n *btreeNode) last() btreeItem {
+	for n.children != nil {
+		n = n.children[len(n.children)-1]
+	}
+	return n.items[len(n.items)-1]
+}
+
+func (n *btreeNode) insertItemAt(i int, item btreeItem) {
+	n.items = append(n.items, btreeItem{})
+	copy(n.items[i+1:], n.items[i:])
+	n.items[i] = item
+}
+
+func (n *btreeNode) removeItemAt(i int) {
+	copy(n.items[i:], n.items[i+1:])
+	n.items[len(n.items)-1] = btreeItem{}
+	n.items = n.items[:len(n.items)-1]
+}
+
+func (n *btreeNode) insertChildAt(i int, child *btreeNode) {
+	n.children = append(n.children, nil)
+	copy(n.children[i+1:], n.children[i:])
+	n.children[i] = child
+}
+
+/**
+	Visits items in the key order
+*/
+
+func (n *btreeNode) walk(fn func(item *btreeItem)) {
+	if n == nil {
+		return
+	}
+	for i := range n.items {
+		if n.children != nil {
+			n.children[i].walk(fn)
+		}
+		fn(&n.items[i])
+	}
+	if n.children != nil {
+		n.children[len(n.items)].walk(fn)
+	}
+}
+
+func (t btreeMapValue) HashMap() map[string]Value {
+	cache := make(map[string]Value)
+	t.root.walk(func(item *btreeItem) {
+		cache[item.key] = item.values[len(item.values)-1]
+	})
+	return cache
+}
+
+func (t btreeMapValue) Entries() []MapEntry {
+	entries := make([]MapEntry, 0, t.size)
+	t.root.walk(func(item *btreeItem) {
+		for _, value := range item.values {
+			entries = append(entries, Entry(item.key, value))
+		}
+	})
+	return entries
+}
+
+func (t btreeMapValue) Keys() []string {
+	var keys []string
+	t.root.walk(func(item *btreeItem) {
+		for range item.values {
+			keys = append(keys, item.key)
+		}
+	})
+	return keys
+}
+
+func (t btreeMapValue) Values() []Value {
+	var values []Value
+	t.root.walk(func(item *btreeItem) {
+		values = append(values, item.values...)
+	})
+	return values
+}
+
+func (t btreeMapValue) Len() int {
+	return t.size
+}
+
+func (t btreeMapValue) Kind() Kind {
+	return MAP
+}
+
+func (t btreeMapValue) Class() reflect.Type {
+	return btreeMapValueClass
+}
+
+func (t btreeMapValue) Object() interface{} {
+	return t.Entries()
+}
+
+func (t btreeMapValue) String() string {
+	var out strings.Builder
+	t.PrintJSON(&out)
+	return out.String()
+}
+
+func (t btreeMapValue) Pack(p Packer) {
+
+	p.PackMap(t.size)
+
+	t.root.walk(func(item *btreeItem) {
+		for _, value := range item.values {
+			p.PackStr(item.key)
+			if value != nil {
+				value.Pack(p)
+			} else {
+				p.PackNil()
+			}
+		}
+	})
+
+}
+
+func (t btreeMapValue) PrintJSON(out *strings.Builder) {
+
+	out.WriteRune('{')
+	first := true
+	t.root.walk(func(item *btreeItem) {
+		for _, value := range item.values {
+			if !first {
+				out.WriteRune(',')
+			}
+			first = false
+			out.WriteString(strconv.Quote(item.key))
+
+			out.WriteString(": ")
+			if value != nil {
+				value.PrintJSON(out)
+			} else {
+				out.WriteString("null")
+			}
+		}
+	})
+	out.WriteRune('}')
+}
+
+func (t btreeMapValue) MarshalJSON() ([]byte, error) {
+	var out strings.Builder
+	t.PrintJSON(&out)
+	return []byte(out.String()), nil
+}
+
+func (t btreeMapValue) MarshalBinary() ([]byte, error) {
+	buf := bytes.Buffer{}
+	p := MessagePacker(&buf)
+	t.Pack(p)
+	return buf.Bytes(), p.Error()
+}
+
+func (t btreeMapValue) Equal(val Value) bool {
+	if val == nil || val.Kind() != MAP {
+		return false
+	}
+	o := val.(Map)
+	if t.Len() != o.Len() {
+		return false
+	}
+	// entries are sorted
+	other := o.Entries()
+	i := 0
+	equal := true
+	t.root.walk(func(item *btreeItem) {
+		for _, value := range item.values {
+			if equal && !Entry(item.key, value).Equal(other[i]) {
+				equal = false
+			}
+			i++
+		}
+	})
+	return equal
+}
+
+func (t btreeMapValue) Get(key string) (Value, bool) {
+	if values, ok := t.lookup(key); ok {
+		return values[0], true
+	}
+	return nil, false
+}
+
+func (t btreeMapValue) GetBool(key string) Bool {
+	value, _ := t.Get(key)
+	if !IsNull(value) {
+		if value.Kind() == BOOL {
+			return value.(Bool)
+		}
+		return ParseBoolean(value.String())
+	}
+	return nil
+}
+
+func (t btreeMapValue) GetNumber(key string) Number {
+	value, _ := t.Get(key)
+	if !IsNull(value) {
+		if value.Kind() == NUMBER {
+			return value.(Number)
+		}
+		return ParseNumber(value.String())
+	}
+	return nil
+}
+
+func (t btreeMapValue) GetString(key string) String {
+	value, _ := t.Get(key)
+	if !IsNull(value) {
+		if value.Kind() == STRING {
+			return value.(String)
+		}
+		return ParseString(value.String())
+	}
+	return nil
+}
+
+func (t btreeMapValue) GetList(key string) List {
+	value, _ := t.Get(key)
+	if !IsNull(value) {
+		switch value.Kind() {
+		case LIST:
+			return value.(List)
+		case MAP:
+			return SolidList(value.(Map).Values())
+		}
+	}
+	return nil
+}
+
+func (t btreeMapValue) GetMap(key string) Map {
+	value, _ := t.Get(key)
+	if !IsNull(value) {
+		switch value.Kind() {
+		case LIST:
+			return SortedMap(value.(List).Entries(), false)
+		case MAP:
+			return value.(Map)
+		}
+	}
+	return nil
+}
+
+func (t btreeMapValue) GetTime(key string) Time {
+	value, _ := t.Get(key)
+	if !IsNull(value) {
+		if value.Kind() == TIME {
+			return value.(Time)
+		}
+		return ParseTime(value.String())
+	}
+	return nil
+}
+
+func (t btreeMapValue) Insert(key string, value Value) Map {
+	return t.InsertAll(key, []Value{value})
+}
+
+func (t btreeMapValue) Put(key string, value Value) Map {
+	old, _ := t.lookup(key)
+	values := make([]Value, len(old))
+	copy(values, old)
+	if len(values) == 0 {
+		values = append(values, value)
+	} else {
+		values[0] = value
+	}
+	return t.set(key, old, values)
+}
+
+func (t btreeMapValue) Remove(key string) Map {
+	old, ok := t.lookup(key)
+	if !ok {
+		return t
+	}
+	return t.set(key, old, old[1:])
+}
+
+func (t btreeMapValue) Select(key string) []Value {
+	old, _ := t.lookup(key)
+	if len(old) == 0 {
+		return nil
+	}
+	return append([]Value(nil), old...)
+}
+
+func (t btreeMapValue) InsertAll(key string, list []Value) Map {
+	if len(list) == 0 {
+		return t
+	}
+	old, _ := t.lookup(key)
+	values := make([]Value, 0, len(list)+len(old))
+	values = append(values, list...)
+	values = append(values, old...)
+	return t.set(key, old, values)
+}
+
+func (t btreeMapValue) DeleteAll(key string) Map {
+	old, _ := t.lookup(key)
+	return t.set(key, old, nil)
+}
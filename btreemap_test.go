@@ -0,0 +1,127 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value_test
+
+import (
+	val "arpabet.pkg.is/value"
+	"github.com/stretchr/testify/require"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+
+func TestBTreeMap(t *testing.T) {
+
+	b := val.EmptyBTreeMap()
+	require.Equal(t, val.MAP, b.Kind())
+	require.Equal(t, "value.btreeMapValue", b.Class().String())
+	require.Equal(t, 0, b.Len())
+	require.Equal(t, "{}", b.String())
+
+	b = b.Put("5", val.Long(5)).Put("name", val.Utf8("name")).Put("123", val.Long(123)).Put("list", testCreateMap().GetList("list"))
+	require.Equal(t, 4, b.Len())
+	require.Equal(t, []string{"123", "5", "list", "name"}, b.Keys())
+
+	m := testCreateMap()
+	require.True(t, m.Equal(b))
+	require.True(t, b.Equal(m))
+	require.Equal(t, val.Jsonify(m), val.Jsonify(b))
+	require.Equal(t, val.Hex(m), val.Hex(b))
+
+	mp, err := val.Pack(b)
+	require.NoError(t, err)
+	c, err := val.Unpack(mp, false)
+	require.NoError(t, err)
+	require.True(t, b.Equal(c))
+
+	require.Equal(t, int64(123), b.GetNumber("123").Long())
+	require.Equal(t, "name", b.GetString("name").String())
+	require.Nil(t, b.GetNumber("none"))
+
+	b = val.BTreeMap([]val.MapEntry{val.Entry("b", val.Long(1)), val.Entry("a", val.Long(2)), val.Entry("b", val.Long(3))})
+	require.Equal(t, []string{"a", "b", "b"}, b.Keys())
+	require.Equal(t, []val.Value{val.Long(1), val.Long(3)}, b.Select("b"))
+
+}
+
+func TestBTreeMapDuplicates(t *testing.T) {
+
+	s := val.EmptyMap()
+	b := val.EmptyBTreeMap()
+
+	apply := func(f func(m val.Map) val.Map) {
+		s, b = f(s), f(b)
+		require.Equal(t, s.Len(), b.Len())
+		require.Equal(t, val.Hex(s), val.Hex(b))
+		require.Equal(t, s.HashMap(), b.HashMap())
+	}
+
+	apply(func(m val.Map) val.Map { return m.Insert("a", val.Long(1)) })
+	apply(func(m val.Map) val.Map { return m.Insert("a", val.Long(2)) })
+	apply(func(m val.Map) val.Map { return m.InsertAll("a", []val.Value{val.Long(3), val.Long(4)}) })
+	apply(func(m val.Map) val.Map { return m.Put("a", val.Long(5)) })
+	apply(func(m val.Map) val.Map { return m.Insert("b", val.Long(6)) })
+	apply(func(m val.Map) val.Map { return m.Remove("a") })
+	require.Equal(t, s.Select("a"), b.Select("a"))
+	apply(func(m val.Map) val.Map { return m.DeleteAll("a") })
+	apply(func(m val.Map) val.Map { return m.Remove("none") })
+	require.Nil(t, b.Select("a"))
+
+}
+
+func TestBTreeMapRandom(t *testing.T) {
+
+	rnd := rand.New(rand.NewSource(1))
+
+	s := val.EmptyMap()
+	b := val.EmptyBTreeMap()
+
+	var versions []val.Map
+	var expected []string
+
+	for i := 0; i < 5000; i++ {
+		key := strconv.Itoa(rnd.Intn(1000))
+		if rnd.Intn(3) == 0 {
+			s, b = s.Remove(key), b.Remove(key)
+		} else {
+			s, b = s.Put(key, val.Long(int64(i))), b.Put(key, val.Long(int64(i)))
+		}
+		if i%500 == 0 {
+			versions = append(versions, b)
+			expected = append(expected, val.Hex(s))
+		}
+	}
+
+	require.Equal(t, s.Len(), b.Len())
+	require.Equal(t, s.Keys(), b.Keys())
+	require.Equal(t, val.Hex(s), val.Hex(b))
+	require.True(t, s.Equal(b))
+
+	for _, key := range s.Keys() {
+		b = b.Remove(key)
+	}
+	require.Equal(t, 0, b.Len())
+	require.Equal(t, "80", val.Hex(b))
+
+	for i, v := range versions {
+		require.Equal(t, expected[i], val.Hex(v))
+	}
+
+}
@@ -0,0 +1,176 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value
+
+import (
+	"sort"
+)
+
+/**
+	Mutable builders of Map and List
+
+	Builders accumulate entries in place and make the immutable value once by Freeze,
+	that is linear for List and n*log(n) for Map instead of the quadratic repeated Put.
+	Builders are not safe for concurrent use.
+*/
+
+type DuplicateKeys int
+
+const (
+
+	/**
+		The last put value wins, like Map.Put
+	*/
+
+	KeepLast DuplicateKeys = iota
+
+	/**
+		The first put value wins
+	*/
+
+	KeepFirst
+
+	/**
+		All values are kept in the put order, like Map.Insert
+	*/
+
+	KeepAll
+)
+
+func (d DuplicateKeys) String() string {
+	switch d {
+	case KeepLast:
+		return "keep_last"
+	case KeepFirst:
+		return "keep_first"
+	case KeepAll:
+		return "keep_all"
+	default:
+		return "unknown"
+	}
+}
+
+type MapBuilder struct {
+	entries []MapEntry
+	policy  DuplicateKeys
+}
+
+/**
+	Creates map builder with the expected number of entries and the policy for duplicate keys
+*/
+
+func NewMapBuilder(capacity int, policy DuplicateKeys) *MapBuilder {
+	return &MapBuilder{
+		entries: make([]MapEntry, 0, capacity),
+		policy:  policy,
+	}
+}
+
+func (b *MapBuilder) Put(key string, value Value) *MapBuilder {
+	b.entries = append(b.entries, Entry(key, value))
+	return b
+}
+
+func (b *MapBuilder) PutAll(m Map) *MapBuilder {
+	b.entries = append(b.entries, m.Entries()...)
+	return b
+}
+
+/**
+	Number of put entries including duplicates
+*/
+
+func (b *MapBuilder) Len() int {
+	return len(b.entries)
+}
+
+/**
+	Sorts entries once, resolves duplicate keys and makes immutable Map, the builder becomes empty
+
+	Capacity of the result is equal to the length, so appends to it never share memory with the builder.
+*/
+
+func (b *MapBuilder) Freeze() Map {
+	entries := b.entries
+	b.entries = nil
+	if len(entries) == 0 {
+		return EmptyMap()
+	}
+	sort.Stable(sortedMapValue(entries))
+	if b.policy != KeepAll {
+		n := 0
+		for i := 0; i < len(entries); i++ {
+			switch {
+			case n == 0 || entries[n-1].Key() != entries[i].Key():
+				entries[n] = entries[i]
+				n++
+			case b.policy == KeepLast:
+				entries[n-1] = entries[i]
+			}
+		}
+		for i := n; i < len(entries); i++ {
+			entries[i] = nil
+		}
+		entries = entries[:n]
+	}
+	return sortedMapValue(entries[:len(entries):len(entries)])
+}
+
+type ListBuilder struct {
+	values []Value
+}
+
+/**
+	Creates list builder with the expected number of elements
+*/
+
+func NewListBuilder(capacity int) *ListBuilder {
+	return &ListBuilder{
+		values: make([]Value, 0, capacity),
+	}
+}
+
+func (b *ListBuilder) Append(val Value) *ListBuilder {
+	b.values = append(b.values, val)
+	return b
+}
+
+func (b *ListBuilder) AppendAll(values ...Value) *ListBuilder {
+	b.values = append(b.values, values...)
+	return b
+}
+
+func (b *ListBuilder) Len() int {
+	return len(b.values)
+}
+
+/**
+	Makes immutable List from the appended elements, the builder becomes empty
+
+	Capacity of the result is equal to the length, so appends to it never share memory with the builder.
+*/
+
+func (b *ListBuilder) Freeze() List {
+	values := b.values
+	b.values = nil
+	if len(values) == 0 {
+		return EmptyList()
+	}
+	return solidListValue(values[:len(values):len(values)])
+}
@@ -0,0 +1,95 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value_test
+
+import (
+	val "arpabet.pkg.is/value"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+
+func TestMapBuilder(t *testing.T) {
+
+	b := val.NewMapBuilder(4, val.KeepLast)
+	b.Put("b", val.Long(1)).Put("a", val.Long(2)).Put("b", val.Long(3))
+	require.Equal(t, 3, b.Len())
+
+	m := b.Freeze()
+	require.Equal(t, 0, b.Len())
+	require.Equal(t, []string{"a", "b"}, m.Keys())
+	require.Equal(t, int64(3), m.GetNumber("b").Long())
+
+	expected := val.EmptyMap().Put("b", val.Long(1)).Put("a", val.Long(2)).Put("b", val.Long(3))
+	require.True(t, expected.Equal(m))
+
+	b = val.NewMapBuilder(0, val.KeepFirst)
+	m = b.Put("b", val.Long(1)).Put("a", val.Long(2)).Put("b", val.Long(3)).Freeze()
+	require.Equal(t, int64(1), m.GetNumber("b").Long())
+	require.Equal(t, 2, m.Len())
+
+	b = val.NewMapBuilder(0, val.KeepAll)
+	m = b.Put("b", val.Long(1)).Put("a", val.Long(2)).Put("b", val.Long(3)).Freeze()
+	require.Equal(t, 3, m.Len())
+	require.Equal(t, []val.Value{val.Long(1), val.Long(3)}, m.Select("b"))
+
+	require.True(t, val.EmptyMap().Equal(val.NewMapBuilder(0, val.KeepLast).Freeze()))
+
+}
+
+func TestMapBuilderNoAliasing(t *testing.T) {
+
+	b := val.NewMapBuilder(10, val.KeepLast)
+	m := b.Put("a", val.Long(1)).Freeze()
+
+	m2 := m.Put("b", val.Long(2))
+	b.Put("c", val.Long(3))
+	m3 := b.Freeze()
+
+	require.Equal(t, []string{"a"}, m.Keys())
+	require.Equal(t, []string{"a", "b"}, m2.Keys())
+	require.Equal(t, []string{"c"}, m3.Keys())
+
+}
+
+func TestListBuilder(t *testing.T) {
+
+	b := val.NewListBuilder(10)
+	for i := 0; i < 5; i++ {
+		b.Append(val.Long(int64(i)))
+	}
+	b.AppendAll(val.Utf8("a"), val.Null())
+	require.Equal(t, 7, b.Len())
+
+	list := b.Freeze()
+	require.Equal(t, 0, b.Len())
+	require.Equal(t, 7, list.Len())
+	require.Equal(t, "[0,1,2,3,4,\"a\",null]", val.Jsonify(list))
+
+	list2 := list.Append(val.Long(5))
+	b.Append(val.Long(6))
+	list3 := b.Freeze()
+
+	require.Equal(t, 7, list.Len())
+	require.Equal(t, int64(5), list2.GetNumberAt(7).Long())
+	require.Equal(t, 1, list3.Len())
+
+	require.Equal(t, 0, val.NewListBuilder(0).Freeze().Len())
+
+}
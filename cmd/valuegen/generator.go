@@ -0,0 +1,644 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/**
+	@author Alex Shvid
+*/
+
+const valuePackage = "arpabet.pkg.is/value"
+
+var valueTypes = map[string]bool {
+	"Value": true,
+	"Bool": true,
+	"Number": true,
+	"String": true,
+	"Time": true,
+	"Extension": true,
+	"List": true,
+	"Map": true,
+}
+
+var basicTypes = map[string]string {
+	"bool": "bool",
+	"int": "int", "int8": "int8", "int16": "int16", "int32": "int32", "int64": "int64", "rune": "int32",
+	"uint": "uint", "uint8": "uint8", "uint16": "uint16", "uint32": "uint32", "uint64": "uint64", "byte": "uint8",
+	"float32": "float32", "float64": "float64",
+	"string": "string",
+}
+
+type typeKind int
+
+const (
+	valueKind typeKind = iota
+	structKind
+	basicKind
+	bytesKind
+	timeKind
+	mapKind
+)
+
+/**
+	Type of the field or element, goType is the type expression in the generated file
+*/
+
+type typeInfo struct {
+	kind       typeKind
+	goType     string
+	basic      string     // underlying basic type for basicKind
+	mapKey     *typeInfo
+	mapElem    *typeInfo
+}
+
+type fieldInfo struct {
+	name       string
+	tag        int
+	array      bool   // slice of elem, packed as list or as repeated tags
+	repeated   bool
+	required   bool
+	elem       *typeInfo
+}
+
+type structInfo struct {
+	name       string
+	fields     []*fieldInfo
+}
+
+type generator struct {
+	pkgName    string
+	specs      map[string]*ast.TypeSpec
+	imports    map[*ast.TypeSpec]map[string]string  // alias to path of the file with the spec
+	structs    map[string]*structInfo
+	queue      []string
+	useTime    bool  // generated code refers to time.Time
+	useFmt     bool
+	buf        bytes.Buffer
+}
+
+/**
+	Generates the source file with PackValue and ParseValue methods for the struct types in the package directory
+*/
+
+func Generate(dir string, typeNames []string) ([]byte, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	var g *generator
+	for name, pkg := range pkgs {
+		candidate := newGenerator(name, pkg)
+		if _, ok := candidate.specs[typeNames[0]]; ok {
+			g = candidate
+			break
+		}
+	}
+	if g == nil {
+		return nil, fmt.Errorf("type '%s' not found in '%s'", typeNames[0], dir)
+	}
+	g.queue = append(g.queue, typeNames...)
+	var order []string
+	for len(g.queue) > 0 {
+		name := g.queue[0]
+		g.queue = g.queue[1:]
+		if _, ok := g.structs[name]; ok {
+			continue
+		}
+		s, err := g.parseStruct(name)
+		if err != nil {
+			return nil, err
+		}
+		g.structs[name] = s
+		order = append(order, name)
+	}
+	for _, name := range order {
+		g.generatePack(g.structs[name])
+		g.generateParse(g.structs[name])
+	}
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by valuegen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\n", g.pkgName)
+	fmt.Fprintf(&out, "import (\n")
+	if g.useFmt {
+		fmt.Fprintf(&out, "\t\"fmt\"\n")
+	}
+	if g.useTime {
+		fmt.Fprintf(&out, "\t\"time\"\n")
+	}
+	fmt.Fprintf(&out, "\n\tvalue %q\n)\n", valuePackage)
+	out.Write(g.buf.Bytes())
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("invalid generated code, %v", err)
+	}
+	return src, nil
+}
+
+func newGenerator(pkgName string, pkg *ast.Package) *generator {
+	g := &generator{
+		pkgName: pkgName,
+		specs:   make(map[string]*ast.TypeSpec),
+		imports: make(map[*ast.TypeSpec]map[string]string),
+		structs: make(map[string]*structInfo),
+	}
+	// sorted file names for deterministic output
+	var fileNames []string
+	for fileName := range pkg.Files {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+	for _, fileName := range fileNames {
+		file := pkg.Files[fileName]
+		imports := make(map[string]string)
+		for _, imp := range file.Imports {
+			path, _ := strconv.Unquote(imp.Path.Value)
+			alias := path[strings.LastIndex(path, "/")+1:]
+			if imp.Name != nil {
+				alias = imp.Name.Name
+			}
+			imports[alias] = path
+		}
+		for _, decl := range file.Decls {
+			if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.TYPE {
+				for _, spec := range gen.Specs {
+					ts := spec.(*ast.TypeSpec)
+					g.specs[ts.Name.Name] = ts
+					g.imports[ts] = imports
+				}
+			}
+		}
+	}
+	return g
+}
+
+func (g *generator) parseStruct(name string) (*structInfo, error) {
+	spec, ok := g.specs[name]
+	if !ok {
+		return nil, fmt.Errorf("type '%s' not found", name)
+	}
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return nil, fmt.Errorf("type '%s' is not a struct", name)
+	}
+	s := &structInfo{name: name}
+	tags := make(map[int]string)
+	for _, field := range st.Fields.List {
+		if len(field.Names) != 1 {
+			return nil, fmt.Errorf("embedded or grouped fields are not supported in '%s'", name)
+		}
+		fieldName := field.Names[0].Name
+		var structTag reflect.StructTag
+		if field.Tag != nil {
+			str, _ := strconv.Unquote(field.Tag.Value)
+			structTag = reflect.StructTag(str)
+		}
+		if _, ok := structTag.Lookup("unknown"); ok {
+			return nil, fmt.Errorf("unknown field '%s' in '%s' is not supported", fieldName, name)
+		}
+		if _, ok := structTag.Lookup("default"); ok {
+			return nil, fmt.Errorf("default of field '%s' in '%s' is not supported", fieldName, name)
+		}
+		tagStr, ok := structTag.Lookup("tag")
+		if !ok {
+			return nil, fmt.Errorf("no tag in field '%s' in '%s'", fieldName, name)
+		}
+		tag, err := strconv.Atoi(tagStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag number '%s' in field '%s' in '%s'", tagStr, fieldName, name)
+		}
+		if other, ok := tags[tag]; ok {
+			return nil, fmt.Errorf("tag %d of field '%s' is used by field '%s' in '%s'", tag, fieldName, other, name)
+		}
+		tags[tag] = fieldName
+		f := &fieldInfo{
+			name: fieldName,
+			tag:  tag,
+		}
+		if rep, ok := structTag.Lookup("repeated"); ok {
+			f.repeated, _ = strconv.ParseBool(rep)
+		}
+		if req, ok := structTag.Lookup("required"); ok {
+			f.required, _ = strconv.ParseBool(req)
+		}
+		fieldType := field.Type
+		if arr, ok := fieldType.(*ast.ArrayType); ok && !isBytes(arr) {
+			if arr.Len != nil {
+				return nil, fmt.Errorf("fixed array field '%s' in '%s' is not supported", fieldName, name)
+			}
+			fieldType = arr.Elt
+			f.array = true
+		}
+		f.elem, err = g.resolve(fieldType, g.imports[spec])
+		if err != nil {
+			return nil, fmt.Errorf("field '%s' in '%s', %v", fieldName, name, err)
+		}
+		s.fields = append(s.fields, f)
+	}
+	sort.SliceStable(s.fields, func(i, j int) bool {
+		return s.fields[i].tag < s.fields[j].tag
+	})
+	return s, nil
+}
+
+func isBytes(arr *ast.ArrayType) bool {
+	if ident, ok := arr.Elt.(*ast.Ident); ok && arr.Len == nil {
+		return ident.Name == "byte" || ident.Name == "uint8"
+	}
+	return false
+}
+
+/**
+	Resolves type expression of the struct field, named types of the package are resolved by the underlying type
+*/
+
+func (g *generator) resolve(expr ast.Expr, imports map[string]string) (*typeInfo, error) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if basic, ok := basicTypes[t.Name]; ok {
+			return &typeInfo{kind: basicKind, goType: t.Name, basic: basic}, nil
+		}
+		spec, ok := g.specs[t.Name]
+		if !ok {
+			return nil, fmt.Errorf("unknown type '%s'", t.Name)
+		}
+		if _, ok := spec.Type.(*ast.StructType); ok {
+			return nil, fmt.Errorf("struct type '%s' must be a pointer", t.Name)
+		}
+		underlying, err := g.resolve(spec.Type, g.imports[spec])
+		if err != nil {
+			return nil, err
+		}
+		switch underlying.kind {
+		case basicKind, bytesKind, mapKind:
+			named := *underlying
+			named.goType = t.Name
+			return &named, nil
+		}
+		return nil, fmt.Errorf("named type '%s' is not supported", t.Name)
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok {
+			switch imports[pkg.Name] {
+			case valuePackage:
+				if valueTypes[t.Sel.Name] {
+					return &typeInfo{kind: valueKind, goType: "value." + t.Sel.Name}, nil
+				}
+			case "time":
+				if t.Sel.Name == "Time" {
+					return &typeInfo{kind: timeKind, goType: "time.Time"}, nil
+				}
+			}
+			return nil, fmt.Errorf("type '%s.%s' is not supported", pkg.Name, t.Sel.Name)
+		}
+	case *ast.StarExpr:
+		if ident, ok := t.X.(*ast.Ident); ok {
+			if spec, ok := g.specs[ident.Name]; ok {
+				if _, ok := spec.Type.(*ast.StructType); ok {
+					g.queue = append(g.queue, ident.Name)
+					return &typeInfo{kind: structKind, goType: "*" + ident.Name}, nil
+				}
+			}
+		}
+	case *ast.ArrayType:
+		if isBytes(t) {
+			return &typeInfo{kind: bytesKind, goType: "[]byte"}, nil
+		}
+	case *ast.MapType:
+		key, err := g.resolve(t.Key, imports)
+		if err != nil {
+			return nil, err
+		}
+		if key.kind != basicKind || key.basic != "string" {
+			return nil, fmt.Errorf("map key must be string, actual '%s'", key.goType)
+		}
+		elem, err := g.resolve(t.Value, imports)
+		if err != nil {
+			return nil, err
+		}
+		if elem.kind != valueKind && elem.kind != basicKind && elem.kind != timeKind {
+			return nil, fmt.Errorf("map element '%s' is not supported", elem.goType)
+		}
+		return &typeInfo{kind: mapKind, goType: "map[" + key.goType + "]" + elem.goType, mapKey: key, mapElem: elem}, nil
+	}
+	return nil, fmt.Errorf("type '%T' is not supported", expr)
+}
+
+func (t *typeInfo) nullable() bool {
+	return t.kind == valueKind || t.kind == structKind || t.kind == bytesKind || t.kind == mapKind
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	if strings.Contains(format, "fmt.") {
+		g.useFmt = true
+	}
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) generatePack(s *structInfo) {
+	g.printf("\n// PackValue packs %s as MessagePack map with tag keys, the same way as value.PackStruct\n", s.name)
+	g.printf("func (t *%s) PackValue(p value.Packer) error {\n", s.name)
+	g.printf("if t == nil {\np.PackNil()\nreturn nil\n}\n")
+	always := 0
+	for _, f := range s.fields {
+		if !f.array && !f.elem.nullable() {
+			always++
+		}
+	}
+	g.printf("cnt := %d\n", always)
+	for _, f := range s.fields {
+		if f.array && f.repeated {
+			g.printf("cnt += len(t.%s)\n", f.name)
+		} else if f.array || f.elem.nullable() {
+			g.printf("if t.%s != nil {\ncnt++\n}\n", f.name)
+		}
+	}
+	g.printf("p.PackMap(cnt)\n")
+	for _, f := range s.fields {
+		field := "t." + f.name
+		switch {
+		case f.array && f.repeated:
+			g.printf("for _, elem := range %s {\n", field)
+			g.printf("p.PackLong(%d)\n", f.tag)
+			g.packElem(f, "elem", false)
+			g.printf("}\n")
+		case f.array:
+			g.printf("if %s != nil {\n", field)
+			g.printf("p.PackLong(%d)\n", f.tag)
+			g.printf("p.PackList(len(%s))\n", field)
+			g.printf("for _, elem := range %s {\n", field)
+			g.packElem(f, "elem", false)
+			g.printf("}\n}\n")
+		case f.elem.nullable():
+			g.printf("if %s != nil {\n", field)
+			g.printf("p.PackLong(%d)\n", f.tag)
+			g.packElem(f, field, true)
+			g.printf("}\n")
+		default:
+			g.printf("p.PackLong(%d)\n", f.tag)
+			g.packElem(f, field, true)
+		}
+	}
+	g.printf("return nil\n}\n")
+}
+
+/**
+	Packs element of the field, notNil is true if the element is already checked
+*/
+
+func (g *generator) packElem(f *fieldInfo, x string, notNil bool) {
+	t := f.elem
+	if t.nullable() && t.kind != structKind && !notNil {
+		if t.kind == valueKind {
+			g.printf("if %s == nil {\n", x)
+			g.printf("return fmt.Errorf(\"can not pack nil element of field %s in %%T\", t)\n", f.name)
+			g.printf("}\n")
+		} else {
+			g.printf("if %s == nil {\np.PackNil()\n} else {\n", x)
+			defer g.printf("}\n")
+		}
+	}
+	switch t.kind {
+	case valueKind:
+		g.printf("%s.Pack(p)\n", x)
+	case structKind:
+		g.printf("if err := %s.PackValue(p); err != nil {\nreturn err\n}\n", x)
+	case bytesKind:
+		g.printf("p.PackBin(%s)\n", x)
+	case mapKind:
+		g.printf("entries := make([]value.MapEntry, 0, len(%s))\n", x)
+		g.printf("for k, v := range %s {\n", x)
+		g.printf("entries = append(entries, value.Entry(%s, %s))\n", cast("string", t.mapKey.goType, "k"), toValue(t.mapElem, "v"))
+		g.printf("}\nvalue.SortedMap(entries, false).Pack(p)\n")
+	default:
+		g.printf("%s.Pack(p)\n", toValue(t, x))
+	}
+}
+
+/**
+	Converts expression x of type from to type to, if the types differ
+*/
+
+func cast(to, from, x string) string {
+	if to == from {
+		return x
+	}
+	return to + "(" + x + ")"
+}
+
+/**
+	Expression converting native or Value element to Value
+*/
+
+func toValue(t *typeInfo, x string) string {
+	switch t.kind {
+	case timeKind:
+		return "value.Timestamp(" + x + ")"
+	case basicKind:
+		switch t.basic {
+		case "bool":
+			return "value.Boolean(" + cast("bool", t.goType, x) + ")"
+		case "string":
+			return "value.Utf8(" + cast("string", t.goType, x) + ")"
+		case "float32":
+			return "value.Float(" + cast("float32", t.goType, x) + ")"
+		case "float64":
+			return "value.Double(" + cast("float64", t.goType, x) + ")"
+		}
+		if strings.HasPrefix(t.basic, "uint") {
+			return "value.Unsigned(" + cast("uint64", t.goType, x) + ")"
+		}
+		return "value.Long(" + cast("int64", t.goType, x) + ")"
+	}
+	return x
+}
+
+func (g *generator) generateParse(s *structInfo) {
+	g.printf("\n// ParseValue parses %s from MessagePack map with tag keys, the same way as value.UnpackStruct\n", s.name)
+	g.printf("func (t *%s) ParseValue(unpacker value.Unpacker, parser value.Parser, options ...value.UnpackOption) error {\n", s.name)
+	g.printf("cnt, err := value.ParseStructHeader(unpacker, parser, %q)\n", s.name)
+	g.printf("if err != nil {\nreturn err\n}\n")
+	for _, f := range s.fields {
+		if f.required {
+			g.printf("seen%s := false\n", f.name)
+		}
+	}
+	g.printf("for i := 0; i < cnt; i++ {\n")
+	g.printf("tag, err := value.ParseStructTag(unpacker, parser, %q, i, options...)\n", s.name)
+	g.printf("if err != nil {\nreturn err\n}\n")
+	g.printf("switch tag {\n")
+	for _, f := range s.fields {
+		path := s.name + "." + f.name
+		field := "t." + f.name
+		g.printf("case %d:\n", f.tag)
+		if f.required {
+			g.printf("seen%s = true\n", f.name)
+		}
+		switch {
+		case f.array && f.repeated && f.elem.kind == structKind:
+			g.printf("elem := new(%s)\n", f.elem.goType[1:])
+			g.parseElem(f.elem, "elem", path)
+			g.printf("%s = append(%s, elem)\n", field, field)
+		case f.array && f.repeated:
+			g.printf("var elem %s\n", g.typeRef(f.elem.goType))
+			g.parseElem(f.elem, "elem", path)
+			g.printf("%s = append(%s, elem)\n", field, field)
+		case f.array:
+			g.printf("n, err := value.ParseArrayHeader(unpacker, parser)\n")
+			g.printf("if err != nil {\nreturn fmt.Errorf(\"parse field '%s' on position %%d, %%v\", i, err)\n}\n", path)
+			g.printf("arr := make([]%s, n)\n", g.typeRef(f.elem.goType))
+			g.printf("for j := 0; j < n; j++ {\n")
+			if f.elem.kind == structKind {
+				g.printf("arr[j] = new(%s)\n", f.elem.goType[1:])
+			}
+			g.parseElem(f.elem, "arr[j]", path)
+			g.printf("}\n%s = arr\n", field)
+		default:
+			if f.elem.kind == structKind {
+				g.printf("if %s == nil {\n%s = new(%s)\n}\n", field, field, f.elem.goType[1:])
+			}
+			g.parseElem(f.elem, field, path)
+		}
+	}
+	g.printf("default:\n")
+	g.printf("if err := value.ParseUnknownTag(unpacker, parser, %q, tag, i, options...); err != nil {\nreturn err\n}\n", s.name)
+	g.printf("}\n}\n")
+	for _, f := range s.fields {
+		if f.required {
+			g.printf("if !seen%s {\n", f.name)
+			g.printf("return fmt.Errorf(\"required field '%s.%s' with tag %d is missing\")\n", s.name, f.name, f.tag)
+			g.printf("}\n")
+		}
+	}
+	g.printf("return nil\n}\n")
+}
+
+/**
+	Parses element of the field into dst, struct elements are allocated by the caller
+*/
+
+func (g *generator) parseElem(t *typeInfo, dst, path string) {
+	if t.kind == structKind {
+		g.printf("if err := %s.ParseValue(unpacker, parser, options...); err != nil {\n", dst)
+		g.printf("return fmt.Errorf(\"parse field '%s' on position %%d, %%v\", i, err)\n}\n", path)
+		return
+	}
+	g.printf("val, err := value.Parse(unpacker, parser, options...)\n")
+	g.printf("if err != nil {\nreturn fmt.Errorf(\"parse field '%s' on position %%d, %%v\", i, err)\n}\n", path)
+	switch t.kind {
+	case valueKind:
+		g.assignValue(t, dst, path)
+	case mapKind:
+		g.printf("if value.IsNull(val) {\n%s = nil\n", dst)
+		g.printf("} else if m, ok := val.(value.Map); !ok {\n")
+		g.printf("return fmt.Errorf(\"fail to set value of field '%s', expected MAP for %s, actual %%v\", val.Kind())\n", path, t.goType)
+		g.printf("} else {\n")
+		g.printf("entries := make(%s, m.Len())\n", g.typeRef(t.goType))
+		g.printf("for _, entry := range m.Entries() {\n")
+		g.printf("var elem %s\n", g.typeRef(t.mapElem.goType))
+		g.printf("val := entry.Value()\n")
+		if t.mapElem.kind == valueKind {
+			g.assignValue(t.mapElem, "elem", path)
+		} else {
+			g.convert(t.mapElem, "elem", path)
+		}
+		g.printf("entries[%s] = elem\n", cast(t.mapKey.goType, "string", "entry.Key()"))
+		g.printf("}\n%s = entries\n}\n", dst)
+	default:
+		g.convert(t, dst, path)
+	}
+}
+
+/**
+	Assigns val to the element of Value type, nil and Null set nil like reflection does
+*/
+
+func (g *generator) assignValue(t *typeInfo, dst, path string) {
+	g.printf("if value.IsNull(val) {\n%s = nil\n", dst)
+	if t.goType == "value.Value" {
+		g.printf("} else {\n%s = val\n}\n", dst)
+		return
+	}
+	g.printf("} else if x, ok := val.(%s); ok {\n%s = x\n", t.goType, dst)
+	g.printf("} else {\n")
+	g.printf("return fmt.Errorf(\"fail to set value of field '%s', expected value type %s, actual %%v\", val.Class())\n", path, t.goType)
+	g.printf("}\n")
+}
+
+/**
+	Converts val to native type by value.Native* functions
+*/
+
+func (g *generator) convert(t *typeInfo, dst, path string) {
+	var call, native string
+	switch t.kind {
+	case timeKind:
+		call, native = "value.NativeTime(val)", "time.Time"
+	case bytesKind:
+		call, native = "value.NativeBytes(val)", "[]byte"
+	default:
+		switch {
+		case t.basic == "bool":
+			call, native = "value.NativeBool(val)", "bool"
+		case t.basic == "string":
+			call, native = "value.NativeString(val)", "string"
+		case strings.HasPrefix(t.basic, "float"):
+			call, native = "value.NativeFloat(val, " + t.basic[5:] + ")", "float64"
+		case strings.HasPrefix(t.basic, "uint"):
+			call, native = "value.NativeUint(val, " + bits(t.basic[4:]) + ")", "uint64"
+		default:
+			call, native = "value.NativeInt(val, " + bits(t.basic[3:]) + ")", "int64"
+		}
+	}
+	g.printf("if x, err := %s; err != nil {\n", call)
+	g.printf("return fmt.Errorf(\"fail to set value of field '%s', %%v\", err)\n", path)
+	g.printf("} else {\n%s = %s\n}\n", dst, g.cast(t.goType, native, "x"))
+}
+
+func (g *generator) cast(to, from, x string) string {
+	if to == from {
+		return x
+	}
+	return g.typeRef(to) + "(" + x + ")"
+}
+
+func (g *generator) typeRef(goType string) string {
+	if strings.Contains(goType, "time.Time") {
+		g.useTime = true
+	}
+	return goType
+}
+
+func bits(suffix string) string {
+	if suffix == "" {
+		return "0"
+	}
+	return suffix
+}
@@ -0,0 +1,93 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package main
+
+import (
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+/**
+	@author Alex Shvid
+*/
+
+func TestGeneratedUpToDate(t *testing.T) {
+
+	src, err := Generate("../..", []string{"GenExample"})
+	require.Nil(t, err)
+
+	expected, err := ioutil.ReadFile("../../structgen_value_test.go")
+	require.Nil(t, err)
+	require.Equal(t, string(expected), string(src), "run go generate")
+
+}
+
+func TestGenerateErrors(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "valuegen")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	src := `package sample
+
+import "arpabet.pkg.is/value"
+
+type Catch struct {
+	Name    string     ` + "`tag:\"1\"`" + `
+	Rest    value.Map  ` + "`unknown:\"true\"`" + `
+}
+
+type Plain struct {
+	Inner   Catch      ` + "`tag:\"1\"`" + `
+}
+
+type Empty struct {
+}
+
+type Dup struct {
+	A       int        ` + "`tag:\"1\"`" + `
+	B       int        ` + "`tag:\"1\"`" + `
+}
+`
+	err = ioutil.WriteFile(filepath.Join(dir, "sample.go"), []byte(src), 0644)
+	require.Nil(t, err)
+
+	_, err = Generate(dir, []string{"Catch"})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "not supported")
+
+	_, err = Generate(dir, []string{"Plain"})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "must be a pointer")
+
+	_, err = Generate(dir, []string{"Dup"})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "tag 1")
+
+	empty, err := Generate(dir, []string{"Empty"})
+	require.Nil(t, err)
+	require.NotContains(t, string(empty), "\"fmt\"")
+
+	_, err = Generate(dir, []string{"Missing"})
+	require.NotNil(t, err)
+
+}
@@ -0,0 +1,79 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+/**
+	Generates reflection-free PackValue and ParseValue methods for tagged structs
+
+	Usage:
+
+		//go:generate valuegen -type=Example
+
+	The generated methods produce the same bytes as PackStruct and are used by PackStruct and UnpackStruct automatically.
+	Struct types referenced by pointer fields are generated in the same file.
+	Fields with `unknown` or `default` tags are not supported, use reflection for such structs.
+
+	@author Alex Shvid
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	typeNames = flag.String("type", "", "comma-separated list of struct names, required")
+	output    = flag.String("output", "", "output file name, default <type>_value.go")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: valuegen -type=T1,T2 [-output file] [directory]\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	types := strings.Split(*typeNames, ",")
+	src, err := Generate(dir, types)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "valuegen: %v\n", err)
+		os.Exit(1)
+	}
+	outputName := *output
+	if outputName == "" {
+		outputName = strings.ToLower(types[0]) + "_value.go"
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, outputName), src, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "valuegen: %v\n", err)
+		os.Exit(1)
+	}
+}
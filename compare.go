@@ -0,0 +1,264 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value
+
+import (
+	"bytes"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+/**
+	Total order of Values
+
+	Kinds are ordered first: nil and Null < BOOL < NUMBER < STRING < TIME < LIST < MAP < UNKNOWN.
+	Within the kind:
+		BOOL     false < true
+		NUMBER   by exact mathematical value regardless of the type, NaN is less than any other number and equal to NaN
+		STRING   bytewise, UTF8 before RAW for equal bytes
+		TIME     chronologically
+		LIST     lexicographically by elements, holes of sparseListValue are nil, solidListValue before sparseListValue for equal elements
+		MAP      lexicographically by entries, key first then value
+		UNKNOWN  bytewise by tag and data
+*/
+
+func kindRank(val Value) int {
+	if val == nil {
+		return 0
+	}
+	switch val.Kind() {
+	case NULL:
+		return 0
+	case BOOL:
+		return 1
+	case NUMBER:
+		return 2
+	case STRING:
+		return 3
+	case TIME:
+		return 4
+	case LIST:
+		return 5
+	case MAP:
+		return 6
+	case UNKNOWN:
+		return 7
+	default:
+		return 8
+	}
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+/**
+	Compares two values, returns -1 if a < b, 0 if a == b, +1 if a > b
+*/
+
+func Compare(a, b Value) int {
+	if cmp := compareInts(kindRank(a), kindRank(b)); cmp != 0 || IsNull(a) {
+		return cmp
+	}
+	switch a.Kind() {
+	case BOOL:
+		return compareBools(a.(Bool).Boolean(), b.(Bool).Boolean())
+	case NUMBER:
+		return compareNumbers(a.(Number), b.(Number))
+	case STRING:
+		return compareStrings(a.(String), b.(String))
+	case TIME:
+		return compareTimes(a.(Time), b.(Time))
+	case LIST:
+		return compareLists(a.(List), b.(List))
+	case MAP:
+		return compareMaps(a.(Map), b.(Map))
+	case UNKNOWN:
+		if x, ok := a.(Extension); ok {
+			if y, ok := b.(Extension); ok {
+				return bytes.Compare(x.Native(), y.Native())
+			}
+		}
+		return 0
+	default:
+		return 0
+	}
+}
+
+/**
+	Checks if a is less than b in the total order
+*/
+
+func Less(a, b Value) bool {
+	return Compare(a, b) < 0
+}
+
+func compareBools(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
+
+func compareNumbers(a, b Number) int {
+	switch {
+	case a.IsNaN() && b.IsNaN():
+		return 0
+	case a.IsNaN():
+		return -1
+	case b.IsNaN():
+		return 1
+	}
+	at, bt := a.Type(), b.Type()
+	if at == LONG && bt == LONG {
+		x, y := a.Long(), b.Long()
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		default:
+			return 0
+		}
+	}
+	if isFloatingType(at) || isFloatingType(bt) {
+		x, y := a.Double(), b.Double()
+		if (isFloatingType(at) && isFloatingType(bt)) || math.IsInf(x, 0) || math.IsInf(y, 0) {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+	return numberRat(a).Cmp(numberRat(b))
+}
+
+/**
+	Exact value of the finite number
+*/
+
+func numberRat(n Number) *big.Rat {
+	switch n.Type() {
+	case LONG:
+		return new(big.Rat).SetInt64(n.Long())
+	case DOUBLE, FLOAT:
+		return new(big.Rat).SetFloat64(n.Double())
+	case ULONG, BIGINT:
+		return new(big.Rat).SetInt(n.BigInt())
+	default:
+		return n.Decimal().Rat()
+	}
+}
+
+func compareStrings(a, b String) int {
+	var cmp int
+	if a.Type() == UTF8 && b.Type() == UTF8 {
+		cmp = strings.Compare(a.Utf8(), b.Utf8())
+	} else {
+		cmp = bytes.Compare(a.Raw(), b.Raw())
+	}
+	if cmp != 0 {
+		return cmp
+	}
+	return compareInts(int(a.Type()), int(b.Type()))
+}
+
+func compareTimes(a, b Time) int {
+	x, y := a.Time(), b.Time()
+	switch {
+	case x.Before(y):
+		return -1
+	case x.After(y):
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareLists(a, b List) int {
+	x, y := a.Values(), b.Values()
+	for i := 0; i < len(x) && i < len(y); i++ {
+		if cmp := Compare(x[i], y[i]); cmp != 0 {
+			return cmp
+		}
+	}
+	if cmp := compareInts(len(x), len(y)); cmp != 0 {
+		return cmp
+	}
+	return compareBools(isSparseList(a), isSparseList(b))
+}
+
+func compareMaps(a, b Map) int {
+	x, y := a.Entries(), b.Entries()
+	for i := 0; i < len(x) && i < len(y); i++ {
+		if cmp := strings.Compare(x[i].Key(), y[i].Key()); cmp != 0 {
+			return cmp
+		}
+		if cmp := Compare(x[i].Value(), y[i].Value()); cmp != 0 {
+			return cmp
+		}
+	}
+	return compareInts(len(x), len(y))
+}
+
+/**
+	Attaches the methods of sort.Interface to []Value, sorting in the total order
+*/
+
+type ValueSlice []Value
+
+func (p ValueSlice) Len() int           { return len(p) }
+func (p ValueSlice) Less(i, j int) bool { return Less(p[i], p[j]) }
+func (p ValueSlice) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+
+/**
+	Sorts values in place in the total order
+*/
+
+func SortValues(values []Value) {
+	sort.Stable(ValueSlice(values))
+}
+
+/**
+	Sorts elements of the List and returns a new solidListValue, the original List stays untouched
+*/
+
+func SortList(list List) List {
+	values := make([]Value, 0, list.Len())
+	values = append(values, list.Values()...)
+	SortValues(values)
+	return SolidList(values)
+}
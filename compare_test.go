@@ -0,0 +1,126 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value_test
+
+import (
+	val "arpabet.pkg.is/value"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompareKinds(t *testing.T) {
+
+	values := []val.Value{
+		val.EmptyMap(),
+		val.Unknown([]byte{5, 1}),
+		val.Tuple(val.Long(1)),
+		val.Utf8("a"),
+		val.Timestamp(time.Unix(0, 0)),
+		val.Long(1),
+		val.True,
+		nil,
+	}
+
+	val.SortValues(values)
+
+	require.Nil(t, values[0])
+	require.Equal(t, val.BOOL, values[1].Kind())
+	require.Equal(t, val.NUMBER, values[2].Kind())
+	require.Equal(t, val.STRING, values[3].Kind())
+	require.Equal(t, val.TIME, values[4].Kind())
+	require.Equal(t, val.LIST, values[5].Kind())
+	require.Equal(t, val.MAP, values[6].Kind())
+	require.Equal(t, val.UNKNOWN, values[7].Kind())
+
+	for i, a := range values {
+		require.Equal(t, 0, val.Compare(a, a))
+		for _, b := range values[i+1:] {
+			require.Equal(t, -1, val.Compare(a, b))
+			require.Equal(t, 1, val.Compare(b, a))
+		}
+	}
+
+}
+
+func TestCompareNumbers(t *testing.T) {
+
+	big1, _ := new(big.Int).SetString("100000000000000000000", 10)
+
+	ordered := []val.Number{
+		val.Nan(),
+		val.Double(math.Inf(-1)),
+		val.Long(math.MinInt64),
+		val.Double(-1.5),
+		val.Long(-1),
+		val.Long(0),
+		val.Decimal(decimal.RequireFromString("0.1")),
+		val.Double(0.1),
+		val.Long(1),
+		val.Long(math.MaxInt64),
+		val.Double(math.MaxInt64),
+		val.BigInt(big1),
+		val.Double(math.Inf(1)),
+	}
+
+	for i := 0; i+1 < len(ordered); i++ {
+		require.True(t, val.Less(ordered[i], ordered[i+1]), "%s < %s", ordered[i], ordered[i+1])
+		require.False(t, val.Less(ordered[i+1], ordered[i]), "%s > %s", ordered[i+1], ordered[i])
+	}
+
+	require.Equal(t, 0, val.Compare(val.Nan(), val.Nan()))
+	require.Equal(t, 0, val.Compare(val.Long(2), val.Double(2)))
+	require.Equal(t, 0, val.Compare(val.Double(0), val.Double(math.Copysign(0, -1))))
+	require.Equal(t, 0, val.Compare(val.Decimal(decimal.RequireFromString("2.50")), val.Double(2.5)))
+	require.Equal(t, 0, val.Compare(val.BigInt(big1), val.Decimal(decimal.NewFromBigInt(big1, 0))))
+
+}
+
+func TestCompareCollections(t *testing.T) {
+
+	require.Equal(t, -1, val.Compare(val.Utf8("a"), val.Utf8("b")))
+	require.Equal(t, -1, val.Compare(val.Utf8("a"), val.Utf8("ab")))
+	require.Equal(t, -1, val.Compare(val.Utf8("z"), val.Raw([]byte{0xff}, false)))
+	require.Equal(t, -1, val.Compare(val.False, val.True))
+
+	require.Equal(t, -1, val.Compare(val.Tuple(val.Long(1)), val.Tuple(val.Long(1), val.Long(0))))
+	require.Equal(t, 1, val.Compare(val.Tuple(val.Long(2)), val.Tuple(val.Long(1), val.Long(0))))
+	require.Equal(t, -1, val.Compare(val.Tuple(val.Long(1)), val.SparseListOf([]val.Value{val.Long(1)})))
+
+	a := val.EmptyMap().Put("a", val.Long(1))
+	require.Equal(t, -1, val.Compare(a, a.Put("a", val.Long(2))))
+	require.Equal(t, -1, val.Compare(a, a.Put("b", val.Long(0))))
+	require.Equal(t, 1, val.Compare(a, val.EmptyMap().Put("0", val.Long(5))))
+	require.Equal(t, 0, val.Compare(testCreateMap(), testCreateMap()))
+
+	list := val.Tuple(val.Utf8("b"), val.Long(3), nil, val.Utf8("a"), val.Long(-1))
+	sorted := val.SortList(list)
+	require.Equal(t, `[null,-1,3,"a","b"]`, strings.ReplaceAll(val.Jsonify(sorted), " ", ""))
+	require.Equal(t, "b", list.GetAt(0).String())
+
+	values := []val.Value{val.Long(2), val.Long(1)}
+	sort.Sort(val.ValueSlice(values))
+	require.Equal(t, int64(1), values[0].(val.Number).Long())
+
+}
@@ -0,0 +1,386 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value
+
+import (
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+)
+
+/**
+	Structural difference between two Values
+
+	Operations are ordered, ApplyDiff applies them one by one, so list indexes
+	of every operation are positions in the list after all previous operations.
+*/
+
+type DiffType int
+
+const (
+	InvalidDiff DiffType = iota
+	ADDED
+	REMOVED
+	CHANGED
+)
+
+func (t DiffType) String() string {
+	switch t {
+	case InvalidDiff:
+		return "invalid"
+	case ADDED:
+		return "added"
+	case REMOVED:
+		return "removed"
+	case CHANGED:
+		return "changed"
+	default:
+		return "unknown"
+	}
+}
+
+type DiffOp struct {
+	Type DiffType
+
+	/**
+		Tokens from root, Map keys or List indexes, empty for root
+	*/
+
+	Path []string
+
+	/**
+		Old value, nil for ADDED
+	*/
+
+	Old Value
+
+	/**
+		New value, nil for REMOVED
+	*/
+
+	New Value
+}
+
+/**
+	Gets path as JSON Pointer (RFC 6901)
+*/
+
+func (op DiffOp) Pointer() string {
+	return formatPointer(op.Path)
+}
+
+func (op DiffOp) String() string {
+	var out strings.Builder
+	out.WriteString(op.Type.String())
+	out.WriteRune(' ')
+	out.WriteString(op.Pointer())
+	switch op.Type {
+	case ADDED:
+		out.WriteString(": ")
+		out.WriteString(Jsonify(op.New))
+	case REMOVED:
+		out.WriteString(": ")
+		out.WriteString(Jsonify(op.Old))
+	case CHANGED:
+		out.WriteString(": ")
+		out.WriteString(Jsonify(op.Old))
+		out.WriteString(" -> ")
+		out.WriteString(Jsonify(op.New))
+	}
+	return out.String()
+}
+
+type DiffOption func(*diffContext)
+
+type diffContext struct {
+	lcs bool
+	ops []DiffOp
+}
+
+/**
+	Compares solidListValue elements by LCS, so insertion in the middle is a single ADDED operation
+
+	Lists with more than maxDiffLCSCells pairs of elements after the common prefix and suffix are compared by position.
+*/
+
+func DiffListLCS() DiffOption {
+	return func(ctx *diffContext) {
+		ctx.lcs = true
+	}
+}
+
+/**
+	Finds structural difference, recurses into Maps by key and into Lists by index
+*/
+
+func Diff(left, right Value, options ...DiffOption) []DiffOp {
+	ctx := &diffContext{}
+	for _, opt := range options {
+		opt(ctx)
+	}
+	ctx.diff(nil, left, right)
+	return ctx.ops
+}
+
+func (ctx *diffContext) add(t DiffType, path []string, old, new Value) {
+	p := make([]string, len(path))
+	copy(p, path)
+	ctx.ops = append(ctx.ops, DiffOp{Type: t, Path: p, Old: old, New: new})
+}
+
+func (ctx *diffContext) diff(path []string, left, right Value) {
+	if Equal(left, right) {
+		return
+	}
+	if left == nil || right == nil || left.Kind() != right.Kind() {
+		ctx.add(CHANGED, path, left, right)
+		return
+	}
+	switch left.Kind() {
+	case MAP:
+		ctx.diffMap(path, left.(Map), right.(Map))
+	case LIST:
+		switch {
+		case isSparseList(left) && isSparseList(right):
+			ctx.diffSparseList(path, left.(List), right.(List))
+		case !isSparseList(left) && !isSparseList(right):
+			if ctx.lcs {
+				ctx.diffListLCS(path, left.(List).Values(), right.(List).Values())
+			} else {
+				ctx.diffList(path, 0, left.(List).Values(), right.(List).Values())
+			}
+		default:
+			ctx.add(CHANGED, path, left, right)
+		}
+	default:
+		ctx.add(CHANGED, path, left, right)
+	}
+}
+
+func (ctx *diffContext) diffMap(path []string, left, right Map) {
+	lkeys := left.Keys()
+	rkeys := right.Keys()
+	if hasDuplicateKeys(lkeys) || hasDuplicateKeys(rkeys) {
+		// operations address entries by key, duplicates are not addressable
+		ctx.add(CHANGED, path, left, right)
+		return
+	}
+	i, j := 0, 0
+	for i < len(lkeys) || j < len(rkeys) {
+		switch {
+		case j == len(rkeys) || (i < len(lkeys) && lkeys[i] < rkeys[j]):
+			old, _ := left.Get(lkeys[i])
+			ctx.add(REMOVED, append(path, lkeys[i]), old, nil)
+			i++
+		case i == len(lkeys) || rkeys[j] < lkeys[i]:
+			new, _ := right.Get(rkeys[j])
+			ctx.add(ADDED, append(path, rkeys[j]), nil, new)
+			j++
+		default:
+			old, _ := left.Get(lkeys[i])
+			new, _ := right.Get(rkeys[j])
+			ctx.diff(append(path, lkeys[i]), old, new)
+			i++
+			j++
+		}
+	}
+}
+
+/**
+	Sorted keys have duplicates next to each other
+*/
+
+func hasDuplicateKeys(keys []string) bool {
+	for i := 1; i < len(keys); i++ {
+		if keys[i-1] == keys[i] {
+			return true
+		}
+	}
+	return false
+}
+
+func uniqueKeys(keys []string) []string {
+	var unique []string
+	for i, key := range keys {
+		if i == 0 || keys[i-1] != key {
+			unique = append(unique, key)
+		}
+	}
+	return unique
+}
+
+func hasDuplicateItems(items []ListItem) bool {
+	for i := 1; i < len(items); i++ {
+		if items[i-1].Key() == items[i].Key() {
+			return true
+		}
+	}
+	return false
+}
+
+func (ctx *diffContext) diffSparseList(path []string, left, right List) {
+	litems := left.Items()
+	ritems := right.Items()
+	if hasDuplicateItems(litems) || hasDuplicateItems(ritems) {
+		ctx.add(CHANGED, path, left, right)
+		return
+	}
+	i, j := 0, 0
+	for i < len(litems) || j < len(ritems) {
+		switch {
+		case j == len(ritems) || (i < len(litems) && litems[i].Key() < ritems[j].Key()):
+			ctx.add(REMOVED, append(path, strconv.Itoa(litems[i].Key())), litems[i].Value(), nil)
+			i++
+		case i == len(litems) || ritems[j].Key() < litems[i].Key():
+			ctx.add(ADDED, append(path, strconv.Itoa(ritems[j].Key())), nil, ritems[j].Value())
+			j++
+		default:
+			ctx.diff(append(path, strconv.Itoa(litems[i].Key())), litems[i].Value(), ritems[j].Value())
+			i++
+			j++
+		}
+	}
+}
+
+/**
+	Compares elements by position, pos is the index of the first element in the list
+*/
+
+func (ctx *diffContext) diffList(path []string, pos int, left, right []Value) {
+	n, m := len(left), len(right)
+	min := n
+	if m < min {
+		min = m
+	}
+	for i := 0; i < min; i++ {
+		ctx.diff(append(path, strconv.Itoa(pos+i)), left[i], right[i])
+	}
+	for i := n; i < m; i++ {
+		ctx.add(ADDED, append(path, strconv.Itoa(pos+i)), nil, right[i])
+	}
+	for i := n - 1; i >= m; i-- {
+		ctx.add(REMOVED, append(path, strconv.Itoa(pos+i)), left[i], nil)
+	}
+}
+
+/**
+	Limit of the LCS table size, larger lists are compared by position after the common prefix and suffix
+*/
+
+const maxDiffLCSCells = 1 << 22
+
+func (ctx *diffContext) diffListLCS(path []string, left, right []Value) {
+
+	// skip common prefix and suffix
+	pos := 0
+	for len(left) > 0 && len(right) > 0 && Equal(left[0], right[0]) {
+		left, right = left[1:], right[1:]
+		pos++
+	}
+	for len(left) > 0 && len(right) > 0 && Equal(left[len(left)-1], right[len(right)-1]) {
+		left, right = left[:len(left)-1], right[:len(right)-1]
+	}
+
+	n, m := len(left), len(right)
+	if m > 0 && n > maxDiffLCSCells/m {
+		ctx.diffList(path, pos, left, right)
+		return
+	}
+
+	// lcs[i][j] is the length of LCS of left[i:] and right[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if Equal(left[i], right[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && Equal(left[i], right[j]):
+			i++
+			j++
+			pos++
+		case i < n && j < m && lcs[i][j] == lcs[i+1][j+1]:
+			// both are not in LCS, change in place
+			ctx.diff(append(path, strconv.Itoa(pos)), left[i], right[j])
+			i++
+			j++
+			pos++
+		case j < m && (i == n || lcs[i][j+1] >= lcs[i+1][j]):
+			ctx.add(ADDED, append(path, strconv.Itoa(pos)), nil, right[j])
+			j++
+			pos++
+		default:
+			ctx.add(REMOVED, append(path, strconv.Itoa(pos)), left[i], nil)
+			i++
+		}
+	}
+}
+
+/**
+	Applies operations to the value and returns a new one, the original value stays untouched
+*/
+
+func ApplyDiff(left Value, diff []DiffOp) (Value, error) {
+	root := left
+	for _, op := range diff {
+		var err error
+		root, err = applyDiffOp(root, op)
+		if err != nil {
+			return nil, errors.Wrapf(err, "apply %s %s", op.Type, op.Pointer())
+		}
+	}
+	return root, nil
+}
+
+func applyDiffOp(root Value, op DiffOp) (Value, error) {
+	if len(op.Path) == 0 {
+		switch op.Type {
+		case ADDED, CHANGED:
+			return op.New, nil
+		case REMOVED:
+			return nil, nil
+		}
+	}
+	switch op.Type {
+	case ADDED:
+		return updatePath(root, op.Path, func(parent Value, token string) (Value, error) {
+			return addChild(parent, token, op.New)
+		})
+	case REMOVED:
+		return updatePath(root, op.Path, removeChild)
+	case CHANGED:
+		return updatePath(root, op.Path, func(parent Value, token string) (Value, error) {
+			return replaceChild(parent, token, op.New)
+		})
+	default:
+		return nil, errors.Errorf("invalid diff type %d", op.Type)
+	}
+}
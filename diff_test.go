@@ -0,0 +1,156 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value_test
+
+import (
+	val "arpabet.pkg.is/value"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func testApplyDiff(t *testing.T, left, right val.Value, options ...val.DiffOption) []val.DiffOp {
+
+	before := val.Jsonify(left)
+
+	diff := val.Diff(left, right, options...)
+
+	actual, err := val.ApplyDiff(left, diff)
+	require.Nil(t, err)
+	require.True(t, val.Equal(right, actual), "expected %s, actual %s", val.Jsonify(right), val.Jsonify(actual))
+	require.Equal(t, before, val.Jsonify(left))
+
+	return diff
+}
+
+func TestDiffMap(t *testing.T) {
+
+	left := testCreateMap()
+	right := left.Put("name", val.Utf8("other")).Remove("5").Put("new", val.True)
+
+	diff := testApplyDiff(t, left, right)
+	require.Equal(t, 3, len(diff))
+
+	require.Equal(t, val.REMOVED, diff[0].Type)
+	require.Equal(t, "/5", diff[0].Pointer())
+	require.True(t, val.Long(5).Equal(diff[0].Old))
+
+	require.Equal(t, val.CHANGED, diff[1].Type)
+	require.Equal(t, "/name", diff[1].Pointer())
+	require.Equal(t, "changed /name: \"name\" -> \"other\"", diff[1].String())
+
+	require.Equal(t, val.ADDED, diff[2].Type)
+	require.Equal(t, "/new", diff[2].Pointer())
+
+	require.Nil(t, val.Diff(left, testCreateMap()))
+
+}
+
+func TestDiffNested(t *testing.T) {
+
+	left := testCreateMap()
+	list := left.GetList("list")
+	right := left.Put("list", list.PutAt(1, val.Long(456)).Append(val.False))
+
+	diff := testApplyDiff(t, left, right)
+	require.Equal(t, 2, len(diff))
+	require.Equal(t, "/list/1", diff[0].Pointer())
+	require.Equal(t, "/list/5", diff[1].Pointer())
+
+	testApplyDiff(t, left, right.Put("list", list.RemoveAt(4).RemoveAt(0)))
+	testApplyDiff(t, left, val.Long(1))
+	testApplyDiff(t, nil, left)
+	testApplyDiff(t, left, nil)
+
+}
+
+func TestDiffSparseList(t *testing.T) {
+
+	left := val.SparseListOf([]val.Value{val.Long(0), nil, val.Long(2)})
+	right := left.RemoveAt(0).PutAt(5, val.Long(5)).PutAt(2, val.Utf8("two"))
+
+	diff := testApplyDiff(t, left, right)
+	require.Equal(t, 3, len(diff))
+	require.Equal(t, "/0", diff[0].Pointer())
+	require.Equal(t, "/2", diff[1].Pointer())
+	require.Equal(t, "/5", diff[2].Pointer())
+
+}
+
+func TestDiffLCS(t *testing.T) {
+
+	var values []val.Value
+	for i := 0; i < 10; i++ {
+		values = append(values, val.Long(int64(i)))
+	}
+	left := val.SolidList(values)
+	right := left.InsertAt(5, val.Utf8("x")).RemoveAt(8).PutAt(0, val.Utf8("y"))
+
+	diff := testApplyDiff(t, left, right)
+	require.True(t, len(diff) > 3)
+
+	diff = testApplyDiff(t, left, right, val.DiffListLCS())
+	require.Equal(t, 3, len(diff))
+	require.Equal(t, val.CHANGED, diff[0].Type)
+	require.Equal(t, "/0", diff[0].Pointer())
+	require.Equal(t, val.ADDED, diff[1].Type)
+	require.Equal(t, "/5", diff[1].Pointer())
+	require.Equal(t, val.REMOVED, diff[2].Type)
+	require.Equal(t, "/8", diff[2].Pointer())
+
+	testApplyDiff(t, val.Tuple(val.Long(1), val.Long(2)), val.Tuple(val.Long(3)), val.DiffListLCS())
+	testApplyDiff(t, val.EmptyList(), left, val.DiffListLCS())
+	testApplyDiff(t, left, val.EmptyList(), val.DiffListLCS())
+
+}
+
+func TestDiffDuplicateKeys(t *testing.T) {
+
+	left := val.EmptyMap().Insert("a", val.Long(1)).Insert("a", val.Long(2)).Put("b", val.Long(3))
+	right := val.EmptyMap().Insert("a", val.Long(1)).Insert("a", val.Long(4)).Put("b", val.Long(3))
+
+	diff := testApplyDiff(t, left, right)
+	require.Equal(t, 1, len(diff))
+	require.Equal(t, val.CHANGED, diff[0].Type)
+
+	testApplyDiff(t, left, left.Remove("a"))
+	testApplyDiff(t, val.EmptyMap().Put("x", left), val.EmptyMap().Put("x", right))
+
+	sparse := val.EmptySparseList().PutAt(1, val.Long(1)).InsertAt(1, val.Long(2))
+	testApplyDiff(t, sparse, val.EmptySparseList().PutAt(1, val.Long(1)))
+	testApplyDiff(t, val.EmptySparseList().PutAt(1, val.Long(1)), sparse)
+
+}
+
+func TestDiffLCSLargeList(t *testing.T) {
+
+	n := 3000
+	left := make([]val.Value, n)
+	for i := 0; i < n; i++ {
+		left[i] = val.Long(int64(i))
+	}
+	right := append([]val.Value{val.Utf8("x")}, left[:n-1]...)
+
+	// too large for LCS table, compared by position
+	diff := testApplyDiff(t, val.SolidList(left), val.SolidList(right), val.DiffListLCS())
+	require.Equal(t, n, len(diff))
+
+	diff = testApplyDiff(t, val.SolidList(left[:100]), val.SolidList(right[:100]), val.DiffListLCS())
+	require.Equal(t, 2, len(diff))
+
+}
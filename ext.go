@@ -0,0 +1,227 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value
+
+import (
+	"github.com/pkg/errors"
+	"sync"
+)
+
+/**
+	Registry of extension types
+
+	Tags are grouped in reserved ranges owned by a library or an application,
+	by default this library owns [0x00, 0x1f] and the MessagePack spec owns [0x80, 0xff],
+	tags outside of any reserved range are free for applications.
+
+	Every tag without decoder is parsed as Unknown value.
+*/
+
+const (
+	LibraryExtOwner = "value"
+	SpecExtOwner    = "msgpack"
+
+	MinLibraryExt Ext = 0x00
+	MaxLibraryExt Ext = 0x1f
+	MinSpecExt    Ext = 0x80
+	MaxSpecExt    Ext = 0xff
+)
+
+type ExtDecoder func(data []byte) (Value, error)
+
+type ExtRange struct {
+	Owner string
+	From  Ext
+	To    Ext
+}
+
+func (r ExtRange) Contains(xtag Ext) bool {
+	return xtag >= r.From && xtag <= r.To
+}
+
+type ExtRegistry struct {
+	mu       sync.RWMutex
+	decoders [256]ExtDecoder
+	reserved []ExtRange
+}
+
+var defaultExtRegistry = newDefaultExtRegistry()
+
+func newDefaultExtRegistry() *ExtRegistry {
+	r := NewExtRegistry()
+	r.Reserve(LibraryExtOwner, MinLibraryExt, MaxLibraryExt)
+	r.Reserve(SpecExtOwner, MinSpecExt, MaxSpecExt)
+	r.RegisterOwned(LibraryExtOwner, BigIntExt, func(data []byte) (Value, error) {
+		v, err := UnpackBigInt(data)
+		return BigInt(v), err
+	})
+	r.RegisterOwned(LibraryExtOwner, DecimalExt, func(data []byte) (Value, error) {
+		v, err := UnpackDecimal(data)
+		return Decimal(v), err
+	})
+	r.RegisterOwned(SpecExtOwner, TimeExt, func(data []byte) (Value, error) {
+		v, err := UnpackTime(data)
+		return Timestamp(v), err
+	})
+	return r
+}
+
+/**
+	Creates empty registry without reserved ranges and decoders
+*/
+
+func NewExtRegistry() *ExtRegistry {
+	return &ExtRegistry{}
+}
+
+/**
+	Gets global registry used by Unpack, Read and ReadStream by default
+*/
+
+func DefaultExtRegistry() *ExtRegistry {
+	return defaultExtRegistry
+}
+
+/**
+	Registers decoder for the application tag in global registry
+*/
+
+func RegisterExt(xtag Ext, decode ExtDecoder) error {
+	return defaultExtRegistry.Register(xtag, decode)
+}
+
+/**
+	Reserves range of tags for the owner in global registry
+*/
+
+func ReserveExt(owner string, from, to Ext) error {
+	return defaultExtRegistry.Reserve(owner, from, to)
+}
+
+/**
+	Makes independent copy of the registry, good for per call overrides
+*/
+
+func (r *ExtRegistry) Copy() *ExtRegistry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c := &ExtRegistry{decoders: r.decoders}
+	c.reserved = append([]ExtRange(nil), r.reserved...)
+	return c
+}
+
+/**
+	Reserves range of tags [from, to] for the owner, ranges of different owners can not overlap
+*/
+
+func (r *ExtRegistry) Reserve(owner string, from, to Ext) error {
+	if from > to {
+		return errors.Errorf("ext: invalid range [%d, %d] for '%s'", from, to, owner)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, rng := range r.reserved {
+		if from <= rng.To && to >= rng.From && rng.Owner != owner {
+			return errors.Errorf("ext: range [%d, %d] for '%s' overlaps range [%d, %d] owned by '%s'", from, to, owner, rng.From, rng.To, rng.Owner)
+		}
+	}
+	r.reserved = append(r.reserved, ExtRange{Owner: owner, From: from, To: to})
+	return nil
+}
+
+/**
+	Gets owner of the tag, or empty string if tag is not reserved
+*/
+
+func (r *ExtRegistry) Owner(xtag Ext) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.owner(xtag)
+}
+
+func (r *ExtRegistry) owner(xtag Ext) string {
+	for _, rng := range r.reserved {
+		if rng.Contains(xtag) {
+			return rng.Owner
+		}
+	}
+	return ""
+}
+
+/**
+	Registers decoder for the free tag, fails on reserved or already registered tag
+*/
+
+func (r *ExtRegistry) Register(xtag Ext, decode ExtDecoder) error {
+	return r.RegisterOwned("", xtag, decode)
+}
+
+/**
+	Registers decoder for the tag in the range reserved by the owner
+*/
+
+func (r *ExtRegistry) RegisterOwned(owner string, xtag Ext, decode ExtDecoder) error {
+	if decode == nil {
+		return errors.Errorf("ext: nil decoder for tag %d", xtag)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if actual := r.owner(xtag); actual != owner {
+		if actual == "" {
+			return errors.Errorf("ext: tag %d is not reserved by '%s'", xtag, owner)
+		}
+		return errors.Errorf("ext: tag %d is reserved by '%s'", xtag, actual)
+	}
+	if r.decoders[xtag] != nil {
+		return errors.Errorf("ext: tag %d already registered", xtag)
+	}
+	r.decoders[xtag] = decode
+	return nil
+}
+
+/**
+	Replaces decoder for any tag without checks, nil decoder makes tag Unknown
+*/
+
+func (r *ExtRegistry) Override(xtag Ext, decode ExtDecoder) {
+	r.mu.Lock()
+	r.decoders[xtag] = decode
+	r.mu.Unlock()
+}
+
+/**
+	Gets decoder for the tag or nil
+*/
+
+func (r *ExtRegistry) Decoder(xtag Ext) ExtDecoder {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.decoders[xtag]
+}
+
+/**
+	Decodes extension, first byte is the xtag, then data
+*/
+
+func (r *ExtRegistry) Decode(tagAndData []byte) (Value, error) {
+	if decode := r.Decoder(Ext(tagAndData[0])); decode != nil {
+		return decode(tagAndData[1:])
+	}
+	return Unknown(tagAndData), nil
+}
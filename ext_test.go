@@ -0,0 +1,92 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value_test
+
+import (
+	"bytes"
+	val "arpabet.pkg.is/value"
+	"github.com/stretchr/testify/require"
+	"math/big"
+	"testing"
+)
+
+func TestExtRegistryReserve(t *testing.T) {
+
+	r := val.DefaultExtRegistry().Copy()
+
+	require.Equal(t, val.LibraryExtOwner, r.Owner(val.BigIntExt))
+	require.Equal(t, val.SpecExtOwner, r.Owner(val.TimeExt))
+	require.Equal(t, "", r.Owner(0x40))
+
+	require.NotNil(t, r.Reserve("app", 0x10, 0x30))
+	require.NotNil(t, r.Reserve("app", 0x30, 0x20))
+	require.Nil(t, r.Reserve("app", 0x20, 0x2f))
+	require.Equal(t, "app", r.Owner(0x25))
+
+	decode := func(data []byte) (val.Value, error) {
+		return val.Utf8(string(data)), nil
+	}
+
+	require.NotNil(t, r.Register(val.MaxExt, decode))
+	require.NotNil(t, r.Register(0x25, decode))
+	require.NotNil(t, r.RegisterOwned("other", 0x25, decode))
+	require.Nil(t, r.RegisterOwned("app", 0x25, decode))
+	require.NotNil(t, r.RegisterOwned("app", 0x25, decode))
+	require.Nil(t, r.Register(0x40, decode))
+	require.NotNil(t, r.Register(0x40, decode))
+
+	require.Equal(t, "", val.DefaultExtRegistry().Owner(0x25))
+
+}
+
+func TestExtRegistryDecode(t *testing.T) {
+
+	mp, err := val.Pack(val.Unknown([]byte{0x41, 'a', 'b', 'c'}))
+	require.Nil(t, err)
+
+	v, err := val.Unpack(mp, false)
+	require.Nil(t, err)
+	require.Equal(t, val.UNKNOWN, v.Kind())
+
+	r := val.DefaultExtRegistry().Copy()
+	require.Nil(t, r.Register(0x41, func(data []byte) (val.Value, error) {
+		return val.Utf8(string(data)), nil
+	}))
+
+	v, err = val.Unpack(mp, false, val.WithExtRegistry(r))
+	require.Nil(t, err)
+	require.True(t, val.Utf8("abc").Equal(v))
+
+	v, err = val.Read(bytes.NewReader(mp), val.WithExtRegistry(r))
+	require.Nil(t, err)
+	require.True(t, val.Utf8("abc").Equal(v))
+
+	mp, err = val.Pack(val.BigInt(big.NewInt(123)))
+	require.Nil(t, err)
+
+	r.Override(val.BigIntExt, nil)
+	v, err = val.Unpack(mp, false, val.WithExtRegistry(r))
+	require.Nil(t, err)
+	require.Equal(t, val.UNKNOWN, v.Kind())
+
+	v, err = val.Unpack(mp, false)
+	require.Nil(t, err)
+	require.Equal(t, val.NUMBER, v.Kind())
+
+}
@@ -0,0 +1,122 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value
+
+import (
+	"database/sql/driver"
+	"github.com/pkg/errors"
+)
+
+/**
+	Holder is a container for any Value that implements unmarshal interfaces
+
+	Use it as a field type in ordinary structs to decode Values
+	with encoding/json, encoding/gob or database/sql.
+*/
+
+type Holder struct {
+	val Value
+}
+
+func Hold(val Value) Holder {
+	return Holder{val}
+}
+
+/**
+	Gets holding value, can be nil
+*/
+
+func (h Holder) Get() Value {
+	return h.val
+}
+
+/**
+	Sets holding value, can be nil
+*/
+
+func (h *Holder) Set(val Value) {
+	h.val = val
+}
+
+func (h Holder) String() string {
+	return Jsonify(h.val)
+}
+
+func (h Holder) MarshalJSON() ([]byte, error) {
+	return []byte(Jsonify(h.val)), nil
+}
+
+func (h *Holder) UnmarshalJSON(data []byte) error {
+	val, err := ParseJSON(data)
+	if err != nil {
+		return err
+	}
+	h.val = val
+	return nil
+}
+
+func (h Holder) MarshalText() ([]byte, error) {
+	return []byte(Jsonify(h.val)), nil
+}
+
+func (h *Holder) UnmarshalText(text []byte) error {
+	return h.UnmarshalJSON(text)
+}
+
+func (h Holder) MarshalBinary() ([]byte, error) {
+	return Pack(h.val)
+}
+
+func (h *Holder) UnmarshalBinary(data []byte) error {
+	val, err := Unpack(data, true)
+	if err != nil {
+		return err
+	}
+	h.val = val
+	return nil
+}
+
+/**
+	Implements driver.Valuer, stores value in MessagePack format
+*/
+
+func (h Holder) Value() (driver.Value, error) {
+	if h.val == nil {
+		return nil, nil
+	}
+	return Pack(h.val)
+}
+
+/**
+	Implements sql.Scanner, accepts MessagePack bytes or JSON string
+*/
+
+func (h *Holder) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		h.val = nil
+		return nil
+	case []byte:
+		return h.UnmarshalBinary(v)
+	case string:
+		return h.UnmarshalJSON([]byte(v))
+	default:
+		return errors.Errorf("holder: unsupported scan type %T", src)
+	}
+}
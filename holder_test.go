@@ -0,0 +1,88 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value_test
+
+import (
+	"bytes"
+	val "arpabet.pkg.is/value"
+	"encoding/gob"
+	"encoding/json"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type testHolderStruct struct {
+	Name  string
+	Value val.Holder
+}
+
+func TestHolderJSON(t *testing.T) {
+
+	s := testHolderStruct{Name: "test", Value: val.Hold(testCreateMap())}
+
+	j, err := json.Marshal(&s)
+	require.Nil(t, err)
+
+	var d testHolderStruct
+	err = json.Unmarshal(j, &d)
+	require.Nil(t, err)
+	require.Equal(t, "test", d.Name)
+	require.True(t, s.Value.Get().Equal(d.Value.Get()))
+
+	err = json.Unmarshal([]byte(`{"Name": "null", "Value": null}`), &d)
+	require.Nil(t, err)
+	require.Nil(t, d.Value.Get())
+
+}
+
+func TestHolderGob(t *testing.T) {
+
+	s := testHolderStruct{Name: "test", Value: val.Hold(testCreateMap())}
+
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(&s)
+	require.Nil(t, err)
+
+	var d testHolderStruct
+	err = gob.NewDecoder(&buf).Decode(&d)
+	require.Nil(t, err)
+	require.True(t, s.Value.Get().Equal(d.Value.Get()))
+
+}
+
+func TestHolderScan(t *testing.T) {
+
+	h := val.Hold(val.Long(123))
+
+	dv, err := h.Value()
+	require.Nil(t, err)
+
+	var d val.Holder
+	require.Nil(t, d.Scan(dv))
+	require.True(t, val.Long(123).Equal(d.Get()))
+
+	require.Nil(t, d.Scan("\"text\""))
+	require.True(t, val.Utf8("text").Equal(d.Get()))
+
+	require.Nil(t, d.Scan(nil))
+	require.Nil(t, d.Get())
+
+	require.NotNil(t, d.Scan(123))
+
+}
@@ -0,0 +1,464 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"github.com/pkg/errors"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+/**
+	JSON reader, inverse of PrintJSON
+
+	Understands the conventions used by PrintJSON:
+		"base64,..."                   -> Raw string
+		"0x..." and "-0x..."           -> BigInt or Decimal number
+		"data:...;base64,..."          -> Unknown extension
+
+	JSON objects always become Maps, sparse lists are printed as
+	objects with decimal keys and therefore come back as Maps.
+*/
+
+type jsonReader struct {
+	r io.ByteScanner
+}
+
+func newJsonReader(r io.Reader) *jsonReader {
+	if bs, ok := r.(io.ByteScanner); ok {
+		return &jsonReader{r: bs}
+	}
+	return &jsonReader{r: bufio.NewReader(r)}
+}
+
+/**
+	Parses JSON document, Jsonify(val) gives back the equal value except the types JSON can not express:
+		TIME                           -> STRING in RFC 3339, use ParseTime to restore
+		FLOAT                          -> DOUBLE
+		NULL                           -> nil
+		NaN and Inf                    -> nil, they are printed as null
+		sparse List                    -> Map with decimal keys
+		STRING with the prefixes above -> Raw string, Number or Unknown extension
+*/
+
+func ParseJSON(data []byte) (Value, error) {
+	jr := newJsonReader(bytes.NewReader(data))
+	val, err := jr.next()
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if c, err := jr.skipSpace(); err != io.EOF {
+		if err != nil {
+			return nil, err
+		}
+		return nil, errors.Errorf("json: unexpected character '%c' after top-level value", c)
+	}
+	return val, nil
+}
+
+func ReadJSON(r io.Reader) (Value, error) {
+	return newJsonReader(r).next()
+}
+
+func ReadJSONStream(r io.Reader, out chan<- Value) error {
+
+	defer close(out)
+
+	jr := newJsonReader(r)
+
+	for {
+
+		value, err := jr.next()
+		if err != nil {
+			return err
+		}
+
+		out <- value
+	}
+}
+
+func (jr *jsonReader) next() (Value, error) {
+	c, err := jr.skipSpace()
+	if err != nil {
+		return nil, err
+	}
+	return jr.parseValue(c)
+}
+
+func (jr *jsonReader) skipSpace() (byte, error) {
+	for {
+		c, err := jr.r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		switch c {
+		case ' ', '\t', '\n', '\r':
+		default:
+			return c, nil
+		}
+	}
+}
+
+func (jr *jsonReader) mustSkipSpace() (byte, error) {
+	c, err := jr.skipSpace()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return c, err
+}
+
+func (jr *jsonReader) parseValue(c byte) (Value, error) {
+	switch {
+	case c == '{':
+		return jr.parseObject()
+	case c == '[':
+		return jr.parseArray()
+	case c == '"':
+		s, err := jr.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return ParseJSONString(s), nil
+	case c == 't':
+		return True, jr.expect("rue")
+	case c == 'f':
+		return False, jr.expect("alse")
+	case c == 'n':
+		return nil, jr.expect("ull")
+	case c == '-' || (c >= '0' && c <= '9'):
+		return jr.parseNumber(c)
+	default:
+		return nil, errors.Errorf("json: unexpected character '%c'", c)
+	}
+}
+
+func (jr *jsonReader) expect(rest string) error {
+	for i := 0; i < len(rest); i++ {
+		c, err := jr.r.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		if c != rest[i] {
+			return errors.Errorf("json: invalid literal, unexpected character '%c'", c)
+		}
+	}
+	return nil
+}
+
+func (jr *jsonReader) parseObject() (Value, error) {
+	var entries []MapEntry
+	c, err := jr.mustSkipSpace()
+	if err != nil {
+		return nil, err
+	}
+	if c == '}' {
+		return EmptyMap(), nil
+	}
+	for {
+		if c != '"' {
+			return nil, errors.Errorf("json: expected object key, but got '%c'", c)
+		}
+		key, err := jr.parseString()
+		if err != nil {
+			return nil, err
+		}
+		if c, err = jr.mustSkipSpace(); err != nil {
+			return nil, err
+		}
+		if c != ':' {
+			return nil, errors.Errorf("json: expected ':' after object key, but got '%c'", c)
+		}
+		value, err := jr.next()
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		entries = append(entries, Entry(key, value))
+		if c, err = jr.mustSkipSpace(); err != nil {
+			return nil, err
+		}
+		switch c {
+		case ',':
+			if c, err = jr.mustSkipSpace(); err != nil {
+				return nil, err
+			}
+		case '}':
+			return SortedMap(entries, false), nil
+		default:
+			return nil, errors.Errorf("json: expected ',' or '}' in object, but got '%c'", c)
+		}
+	}
+}
+
+func (jr *jsonReader) parseArray() (Value, error) {
+	var list []Value
+	c, err := jr.mustSkipSpace()
+	if err != nil {
+		return nil, err
+	}
+	if c == ']' {
+		return EmptyList(), nil
+	}
+	for {
+		value, err := jr.parseValue(c)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+		if c, err = jr.mustSkipSpace(); err != nil {
+			return nil, err
+		}
+		switch c {
+		case ',':
+			if c, err = jr.mustSkipSpace(); err != nil {
+				return nil, err
+			}
+		case ']':
+			return SolidList(list), nil
+		default:
+			return nil, errors.Errorf("json: expected ',' or ']' in array, but got '%c'", c)
+		}
+	}
+}
+
+func (jr *jsonReader) parseNumber(first byte) (Value, error) {
+	var buf strings.Builder
+	buf.WriteByte(first)
+	for {
+		c, err := jr.r.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if (c >= '0' && c <= '9') || c == '.' || c == 'e' || c == 'E' || c == '+' || c == '-' {
+			buf.WriteByte(c)
+		} else {
+			if err := jr.r.UnreadByte(); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	str := buf.String()
+	if !isJSONNumber(str) {
+		return nil, errors.Errorf("json: invalid number '%s'", str)
+	}
+	num := ParseNumber(str)
+	if num.IsNaN() {
+		return nil, errors.Errorf("json: invalid number '%s'", str)
+	}
+	return num, nil
+}
+
+/**
+	Checks the number grammar of RFC 8259, no leading zeros, no leading plus, digits around the point
+*/
+
+func isJSONNumber(s string) bool {
+	i := 0
+	if i < len(s) && s[i] == '-' {
+		i++
+	}
+	digits := func() int {
+		start := i
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+		return i - start
+	}
+	if i < len(s) && s[i] == '0' {
+		i++
+	} else if digits() == 0 {
+		return false
+	}
+	if i < len(s) && s[i] == '.' {
+		i++
+		if digits() == 0 {
+			return false
+		}
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		if digits() == 0 {
+			return false
+		}
+	}
+	return i == len(s)
+}
+
+/**
+	Reads the string after the opening quote
+
+	Accepts JSON escapes and also Go escapes produced by strconv.Quote in PrintJSON,
+	raw control characters are not allowed by RFC 8259
+*/
+
+func (jr *jsonReader) parseString() (string, error) {
+	var out []byte
+	for {
+		c, err := jr.r.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				return "", io.ErrUnexpectedEOF
+			}
+			return "", err
+		}
+		switch c {
+		case '"':
+			return string(out), nil
+		case '\\':
+			if out, err = jr.parseEscape(out); err != nil {
+				return "", err
+			}
+		default:
+			if c < 0x20 {
+				return "", errors.Errorf("json: control character 0x%02x in string", c)
+			}
+			out = append(out, c)
+		}
+	}
+}
+
+func (jr *jsonReader) parseEscape(out []byte) ([]byte, error) {
+	c, err := jr.r.ReadByte()
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	switch c {
+	case '"', '\\', '/', '\'':
+		return append(out, c), nil
+	case 'b':
+		return append(out, '\b'), nil
+	case 'f':
+		return append(out, '\f'), nil
+	case 'n':
+		return append(out, '\n'), nil
+	case 'r':
+		return append(out, '\r'), nil
+	case 't':
+		return append(out, '\t'), nil
+	case 'a':
+		return append(out, '\a'), nil
+	case 'v':
+		return append(out, '\v'), nil
+	case 'x':
+		v, err := jr.readHex(2)
+		if err != nil {
+			return nil, err
+		}
+		return append(out, byte(v)), nil
+	case 'U':
+		v, err := jr.readHex(8)
+		if err != nil {
+			return nil, err
+		}
+		return appendRune(out, rune(v)), nil
+	case 'u':
+		v, err := jr.readHex(4)
+		if err != nil {
+			return nil, err
+		}
+		r := rune(v)
+		if r >= 0xd800 && r < 0xdc00 {
+			// surrogate pair
+			if err := jr.expect("\\u"); err != nil {
+				return nil, err
+			}
+			low, err := jr.readHex(4)
+			if err != nil {
+				return nil, err
+			}
+			if low < 0xdc00 || low >= 0xe000 {
+				return nil, errors.Errorf("json: invalid surrogate pair \\u%04x\\u%04x", v, low)
+			}
+			r = ((r - 0xd800) << 10) + (rune(low) - 0xdc00) + 0x10000
+		}
+		return appendRune(out, r), nil
+	default:
+		return nil, errors.Errorf("json: invalid escape character '%c'", c)
+	}
+}
+
+func (jr *jsonReader) readHex(n int) (uint32, error) {
+	var buf [8]byte
+	for i := 0; i < n; i++ {
+		c, err := jr.r.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				return 0, io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		buf[i] = c
+	}
+	v, err := strconv.ParseUint(string(buf[:n]), 16, 32)
+	if err != nil {
+		return 0, errors.Errorf("json: invalid hex escape '%s'", string(buf[:n]))
+	}
+	return uint32(v), nil
+}
+
+func appendRune(out []byte, r rune) []byte {
+	var buf [utf8.UTFMax]byte
+	n := utf8.EncodeRune(buf[:], r)
+	return append(out, buf[:n]...)
+}
+
+/**
+	Converts JSON string to Value by using PrintJSON conventions
+*/
+
+func ParseJSONString(str string) Value {
+	if strings.HasPrefix(str, UnknownPrefix) {
+		rest := str[len(UnknownPrefix):]
+		if strings.HasPrefix(rest, Base64Prefix) {
+			tagAndData, err := base64.RawStdEncoding.DecodeString(rest[len(Base64Prefix):])
+			if err == nil && len(tagAndData) > 0 {
+				return Unknown(tagAndData)
+			}
+		}
+	}
+	if hasHexPrefix(str) {
+		if val, err := parseHexNumber(str); err == nil {
+			return val
+		}
+	}
+	return ParseString(str)
+}
@@ -0,0 +1,165 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value_test
+
+import (
+	val "arpabet.pkg.is/value"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"io"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testJsonRoundTrip(t *testing.T, v val.Value) {
+
+	actual, err := val.ParseJSON([]byte(val.Jsonify(v)))
+	require.Nil(t, err)
+	require.True(t, v.Equal(actual), "expected %s, actual %s", val.Jsonify(v), val.Jsonify(actual))
+
+}
+
+func TestParseJSONPrimitives(t *testing.T) {
+
+	testJsonRoundTrip(t, val.True)
+	testJsonRoundTrip(t, val.False)
+	testJsonRoundTrip(t, val.Long(-123))
+	testJsonRoundTrip(t, val.Double(-12.34))
+	testJsonRoundTrip(t, val.Utf8("text"))
+	testJsonRoundTrip(t, val.Utf8("json\"val\"json\n\x01"))
+	testJsonRoundTrip(t, val.Raw([]byte{0, 1, 2}, false))
+	testJsonRoundTrip(t, val.BigInt(big.NewInt(-1234567)))
+	testJsonRoundTrip(t, val.Decimal(decimal.New(12345, -2)))
+	testJsonRoundTrip(t, val.Unknown([]byte{byte(val.MaxExt), 1, 2}))
+
+	v, err := val.ParseJSON([]byte(" null "))
+	require.Nil(t, err)
+	require.Nil(t, v)
+
+}
+
+func TestParseJSONCollections(t *testing.T) {
+
+	testJsonRoundTrip(t, val.EmptyList())
+	testJsonRoundTrip(t, val.EmptyMap())
+	testJsonRoundTrip(t, testCreateMap())
+
+	v, err := val.ParseJSON([]byte(`{"b": [1, 2.5, "x", true, null], "a": {"c": "é😀"}}`))
+	require.Nil(t, err)
+	require.Equal(t, val.MAP, v.Kind())
+
+	m := v.(val.Map)
+	require.Equal(t, []string{"a", "b"}, m.Keys())
+	require.Equal(t, "é😀", m.GetMap("a").GetString("c").String())
+
+	list := m.GetList("b")
+	require.Equal(t, 5, list.Len())
+	require.Equal(t, val.LONG, list.GetNumberAt(0).Type())
+	require.Equal(t, val.DOUBLE, list.GetNumberAt(1).Type())
+	require.Nil(t, list.GetAt(4))
+
+}
+
+func TestJSONEscapeKeys(t *testing.T) {
+
+	for _, m := range []val.Map{val.EmptyMap(), val.EmptyBTreeMap()} {
+		m = m.Put("a\"b", val.Long(1)).Put("c\\d\n", val.Decimal(decimal.New(150, -2)))
+		require.Equal(t, `{"a\"b": 1,"c\\d\n": "0x96x-02"}`, val.Jsonify(m))
+		require.Equal(t, `{"a\"b": 1,"c\\d\n": 1.50}`, val.JsonifyWith(m, val.PlainNumbers))
+		testJsonRoundTrip(t, m)
+
+		actual, err := val.ParseJSON([]byte(val.JsonifyWith(m, val.PlainNumbers)))
+		require.Nil(t, err)
+		require.True(t, m.Equal(actual))
+	}
+
+}
+
+func TestParseJSONLossyTypes(t *testing.T) {
+
+	tm := val.Timestamp(time.Date(2020, 1, 2, 3, 4, 5, 6, time.UTC))
+	v, err := val.ParseJSON([]byte(val.Jsonify(tm)))
+	require.Nil(t, err)
+	require.Equal(t, val.STRING, v.Kind())
+	require.False(t, tm.Equal(v))
+	require.True(t, tm.Equal(val.ParseTime(v.String())))
+
+	v, err = val.ParseJSON([]byte(val.Jsonify(val.Float(1.5))))
+	require.Nil(t, err)
+	require.Equal(t, val.DOUBLE, v.(val.Number).Type())
+
+	for _, lossy := range []val.Value{val.Null(), val.Double(math.NaN()), val.Double(math.Inf(-1)), val.Float(float32(math.Inf(1)))} {
+		v, err = val.ParseJSON([]byte(val.Jsonify(lossy)))
+		require.Nil(t, err)
+		require.Nil(t, v)
+	}
+
+	v, err = val.ParseJSON([]byte(val.Jsonify(val.SparseList([]val.ListItem{val.Item(2, val.Long(1))}, true))))
+	require.Nil(t, err)
+	require.Equal(t, val.MAP, v.Kind())
+
+	v, err = val.ParseJSON([]byte(val.Jsonify(val.Utf8("0x10"))))
+	require.Nil(t, err)
+	require.Equal(t, val.NUMBER, v.Kind())
+
+}
+
+func TestParseJSONErrors(t *testing.T) {
+
+	for _, s := range []string{"", "{", "[1,", "{\"a\" 1}", "tru", "1 2", "\"abc", "-", "[1,]"} {
+		_, err := val.ParseJSON([]byte(s))
+		require.NotNil(t, err, s)
+	}
+
+	// RFC 8259 numbers and strings
+	for _, s := range []string{"[01]", "-01", "00", "1.", ".5", "1e", "1e+", "-", "1.5.2", "1-2", "\"a\tb\"", "\"a\x01b\"", "{\"a\nb\": 1}"} {
+		_, err := val.ParseJSON([]byte(s))
+		require.NotNil(t, err, s)
+	}
+
+	for _, s := range []string{"0", "-0", "0.5", "-0.5e-3", "10E+2", "\"a\\tb\""} {
+		_, err := val.ParseJSON([]byte(s))
+		require.Nil(t, err, s)
+	}
+
+}
+
+func TestReadJSONStream(t *testing.T) {
+
+	r := strings.NewReader(`{"a": 1} [2] "three"`)
+
+	valueC := make(chan val.Value)
+	errC := make(chan error, 1)
+	go func() {
+		errC <- val.ReadJSONStream(r, valueC)
+	}()
+
+	var actual []val.Value
+	for v := range valueC {
+		actual = append(actual, v)
+	}
+
+	require.Equal(t, io.EOF, <-errC)
+	require.Equal(t, 3, len(actual))
+	require.True(t, val.Utf8("three").Equal(actual[2]))
+
+}
@@ -0,0 +1,548 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value
+
+import (
+	"encoding/binary"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/**
+	Order-preserving binary key encoding
+
+	Bytewise order of encoded keys matches the order of Compare.
+	Top-level solidListValue is a tuple, its elements are concatenated without a header,
+	so the key of Tuple(a) is a prefix of the key of Tuple(a, b) and can be used as a range-scan bound.
+
+	Element layout:
+		0x05                          nil
+		0x10 | 0x11                   false | true
+		0x20                          NaN
+		0x21 | 0x25                   -Inf | +Inf
+		0x22 exp digits 0x00 type     negative number, all bytes except type are inverted
+		0x23 type                     zero
+		0x24 exp digits 0x00 type     positive number 0.digits * 10^exp, digit is encoded as d+1
+		0x30 bytes 0x00 type          string, byte 0x00 is escaped as 0x00 0xff
+		0x38 seconds nanos            time
+		0x40 elements 0x00 flavour    nested list, holes of sparseListValue are nil
+		0x50 (0x01 key value)* 0x00   map
+		0x60 bytes 0x00               unknown extension with tag
+*/
+
+const (
+	keyNil      = 0x05
+	keyFalse    = 0x10
+	keyTrue     = 0x11
+	keyNaN      = 0x20
+	keyNegInf   = 0x21
+	keyNegative = 0x22
+	keyZero     = 0x23
+	keyPositive = 0x24
+	keyPosInf   = 0x25
+	keyString   = 0x30
+	keyTime     = 0x38
+	keyList     = 0x40
+	keyMap      = 0x50
+	keyUnknown  = 0x60
+
+	keyEnd    = 0x00
+	keyEscape = 0xff
+	keyEntry  = 0x01
+
+	keySolidList  = 0x01
+	keySparseList = 0x02
+)
+
+/**
+	Encodes value to the key, solidListValue is encoded as a tuple of its elements
+*/
+
+func EncodeKey(val Value) []byte {
+	var buf []byte
+	if val != nil && val.Kind() == LIST && !isSparseList(val) {
+		for _, elem := range val.(List).Values() {
+			buf = appendKey(buf, elem)
+		}
+		return buf
+	}
+	return appendKey(buf, val)
+}
+
+/**
+	Decodes the key to the tuple of elements
+
+	Decimals are normalized, trailing zeros of the coefficient are not preserved.
+*/
+
+func DecodeKey(key []byte) (List, error) {
+	d := &keyDecoder{buf: key}
+	var values []Value
+	for !d.eof() {
+		val, err := d.next()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, val)
+	}
+	if len(values) == 0 {
+		return EmptyList(), nil
+	}
+	return SolidList(values), nil
+}
+
+func appendKey(buf []byte, val Value) []byte {
+	if IsNull(val) {
+		return append(buf, keyNil)
+	}
+	switch val.Kind() {
+	case BOOL:
+		if val.(Bool).Boolean() {
+			return append(buf, keyTrue)
+		}
+		return append(buf, keyFalse)
+	case NUMBER:
+		return appendKeyNumber(buf, val.(Number))
+	case STRING:
+		s := val.(String)
+		buf = append(buf, keyString)
+		if s.Type() == UTF8 {
+			buf = appendKeyString(buf, s.Utf8())
+		} else {
+			buf = appendKeyBytes(buf, s.Raw())
+		}
+		return append(buf, byte(s.Type()))
+	case TIME:
+		t := val.(Time).Time()
+		buf = append(buf, keyTime)
+		buf = appendKeyUint64(buf, uint64(t.Unix())^(1<<63))
+		return appendKeyUint32(buf, uint32(t.Nanosecond()))
+	case LIST:
+		buf = append(buf, keyList)
+		for _, elem := range val.(List).Values() {
+			buf = appendKey(buf, elem)
+		}
+		if isSparseList(val) {
+			return append(buf, keyEnd, keySparseList)
+		}
+		return append(buf, keyEnd, keySolidList)
+	case MAP:
+		buf = append(buf, keyMap)
+		for _, entry := range val.(Map).Entries() {
+			buf = append(buf, keyEntry)
+			buf = appendKeyString(buf, entry.Key())
+			buf = appendKey(buf, entry.Value())
+		}
+		return append(buf, keyEnd)
+	default:
+		buf = append(buf, keyUnknown)
+		if ext, ok := val.(Extension); ok {
+			buf = appendKeyBytes(buf, ext.Native())
+		} else {
+			buf = append(buf, keyEnd)
+		}
+		return buf
+	}
+}
+
+func appendKeyUint64(buf []byte, v uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], v)
+	return append(buf, b[:]...)
+}
+
+func appendKeyUint32(buf []byte, v uint32) []byte {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], v)
+	return append(buf, b[:]...)
+}
+
+func appendKeyBytes(buf []byte, b []byte) []byte {
+	for _, ch := range b {
+		buf = append(buf, ch)
+		if ch == keyEnd {
+			buf = append(buf, keyEscape)
+		}
+	}
+	return append(buf, keyEnd)
+}
+
+func appendKeyString(buf []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		buf = append(buf, s[i])
+		if s[i] == keyEnd {
+			buf = append(buf, keyEscape)
+		}
+	}
+	return append(buf, keyEnd)
+}
+
+func appendKeyNumber(buf []byte, n Number) []byte {
+	if n.IsNaN() {
+		return append(buf, keyNaN)
+	}
+	if isFloatingType(n.Type()) && math.IsInf(n.Double(), 0) {
+		if n.Double() < 0 {
+			return append(buf, keyNegInf)
+		}
+		return append(buf, keyPosInf)
+	}
+	neg, digits, exp := numberDigits(n)
+	if digits == "" {
+		return append(buf, keyZero, byte(n.Type()))
+	}
+	tag := byte(keyPositive)
+	if neg {
+		tag = keyNegative
+	}
+	buf = append(buf, tag)
+	start := len(buf)
+	buf = appendKeyUint32(buf, uint32(int32(exp))^(1<<31))
+	for i := 0; i < len(digits); i++ {
+		buf = append(buf, digits[i]-'0'+1)
+	}
+	buf = append(buf, keyEnd)
+	if neg {
+		for i := start; i < len(buf); i++ {
+			buf[i] = ^buf[i]
+		}
+	}
+	return append(buf, byte(n.Type()))
+}
+
+/**
+	Exact value of the finite number as ±0.digits * 10^exp, digits have no leading and trailing zeros, empty for zero
+*/
+
+func numberDigits(n Number) (neg bool, digits string, exp int64) {
+	var scale int64
+	switch n.Type() {
+	case LONG:
+		v := n.Long()
+		u := uint64(v)
+		if v < 0 {
+			neg, u = true, uint64(^v)+1
+		}
+		digits = strconv.FormatUint(u, 10)
+	case ULONG:
+		digits = strconv.FormatUint(n.Uint64(), 10)
+	case DOUBLE, FLOAT:
+		f := n.Double()
+		if f < 0 {
+			neg, f = true, -f
+		}
+		mant, e2 := math.Frexp(f)
+		m := new(big.Int).SetUint64(uint64(mant * (1 << 53)))
+		e2 -= 53
+		if e2 >= 0 {
+			m.Lsh(m, uint(e2))
+		} else {
+			// m * 2^-k = m * 5^k * 10^-k
+			k := int64(-e2)
+			m.Mul(m, new(big.Int).Exp(big.NewInt(5), big.NewInt(k), nil))
+			scale = -k
+		}
+		digits = m.String()
+	case BIGINT:
+		b := n.BigInt()
+		neg = b.Sign() < 0
+		digits = new(big.Int).Abs(b).String()
+	default:
+		d := n.Decimal()
+		neg = d.Sign() < 0
+		digits = new(big.Int).Abs(d.Coefficient()).String()
+		scale = int64(d.Exponent())
+	}
+	trimmed := strings.TrimRight(digits, "0")
+	if trimmed == "" {
+		return false, "", 0
+	}
+	scale += int64(len(digits) - len(trimmed))
+	return neg, trimmed, int64(len(trimmed)) + scale
+}
+
+type keyDecoder struct {
+	buf []byte
+	pos int
+}
+
+func (d *keyDecoder) eof() bool {
+	return d.pos >= len(d.buf)
+}
+
+func (d *keyDecoder) errorf(format string, args ...interface{}) error {
+	return errors.Wrapf(errors.Errorf(format, args...), "invalid key at %d", d.pos)
+}
+
+func (d *keyDecoder) readByte() (byte, error) {
+	if d.eof() {
+		return 0, d.errorf("unexpected end of key")
+	}
+	b := d.buf[d.pos]
+	d.pos++
+	return b, nil
+}
+
+func (d *keyDecoder) readFixed(n int) ([]byte, error) {
+	if d.pos+n > len(d.buf) {
+		return nil, d.errorf("unexpected end of key")
+	}
+	b := d.buf[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *keyDecoder) readBytes() ([]byte, error) {
+	var out []byte
+	for {
+		b, err := d.readByte()
+		if err != nil {
+			return nil, err
+		}
+		if b != keyEnd {
+			out = append(out, b)
+			continue
+		}
+		if !d.eof() && d.buf[d.pos] == keyEscape {
+			d.pos++
+			out = append(out, keyEnd)
+			continue
+		}
+		return out, nil
+	}
+}
+
+func (d *keyDecoder) next() (Value, error) {
+	tag, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case keyNil:
+		return nil, nil
+	case keyFalse:
+		return False, nil
+	case keyTrue:
+		return True, nil
+	case keyNaN:
+		return Nan(), nil
+	case keyNegInf:
+		return Double(math.Inf(-1)), nil
+	case keyPosInf:
+		return Double(math.Inf(1)), nil
+	case keyZero:
+		t, err := d.readByte()
+		if err != nil {
+			return nil, err
+		}
+		return keyNumber(NumberType(t), false, "", 0)
+	case keyNegative, keyPositive:
+		return d.readNumber(tag == keyNegative)
+	case keyString:
+		b, err := d.readBytes()
+		if err != nil {
+			return nil, err
+		}
+		t, err := d.readByte()
+		if err != nil {
+			return nil, err
+		}
+		switch StringType(t) {
+		case UTF8:
+			return Utf8(string(b)), nil
+		case RAW:
+			return Raw(b, false), nil
+		default:
+			return nil, d.errorf("invalid string type %d", t)
+		}
+	case keyTime:
+		b, err := d.readFixed(12)
+		if err != nil {
+			return nil, err
+		}
+		sec := int64(binary.BigEndian.Uint64(b) ^ (1 << 63))
+		nsec := int64(binary.BigEndian.Uint32(b[8:]))
+		return Timestamp(time.Unix(sec, nsec).UTC()), nil
+	case keyList:
+		return d.readList()
+	case keyMap:
+		return d.readMap()
+	case keyUnknown:
+		b, err := d.readBytes()
+		if err != nil {
+			return nil, err
+		}
+		return Unknown(b), nil
+	default:
+		return nil, d.errorf("invalid tag 0x%02x", tag)
+	}
+}
+
+func (d *keyDecoder) readNumber(neg bool) (Value, error) {
+	b, err := d.readFixed(4)
+	if err != nil {
+		return nil, err
+	}
+	mask := byte(0)
+	if neg {
+		mask = 0xff
+	}
+	e := [4]byte{b[0] ^ mask, b[1] ^ mask, b[2] ^ mask, b[3] ^ mask}
+	exp := int64(int32(binary.BigEndian.Uint32(e[:]) ^ (1 << 31)))
+	var digits strings.Builder
+	for {
+		ch, err := d.readByte()
+		if err != nil {
+			return nil, err
+		}
+		ch ^= mask
+		if ch == keyEnd {
+			break
+		}
+		if ch < 1 || ch > 10 {
+			return nil, d.errorf("invalid digit 0x%02x", ch)
+		}
+		digits.WriteByte('0' + ch - 1)
+	}
+	t, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+	num, err := keyNumber(NumberType(t), neg, digits.String(), exp)
+	if err != nil {
+		return nil, d.errorf("%v", err)
+	}
+	return num, nil
+}
+
+func keyNumber(t NumberType, neg bool, digits string, exp int64) (Number, error) {
+	if isFloatingType(t) {
+		var f float64
+		if digits != "" {
+			str := "0." + digits + "e" + strconv.FormatInt(exp, 10)
+			if neg {
+				str = "-" + str
+			}
+			var err error
+			if f, err = strconv.ParseFloat(str, 64); err != nil {
+				return nil, err
+			}
+		}
+		if t == FLOAT {
+			return Float(float32(f)), nil
+		}
+		return Double(f), nil
+	}
+	coef := new(big.Int)
+	if digits != "" {
+		coef.SetString(digits, 10)
+	}
+	if neg {
+		coef.Neg(coef)
+	}
+	scale := exp - int64(len(digits))
+	switch t {
+	case DECIMAL:
+		return Decimal(decimal.NewFromBigInt(coef, int32(scale))), nil
+	case LONG, ULONG, BIGINT:
+		if scale < 0 {
+			return nil, errors.Errorf("fractional %s", t)
+		}
+		coef.Mul(coef, new(big.Int).Exp(big.NewInt(10), big.NewInt(scale), nil))
+		switch {
+		case t == BIGINT:
+			return BigInt(coef), nil
+		case t == ULONG:
+			if !coef.IsUint64() {
+				return nil, errors.Errorf("ulong overflow")
+			}
+			return ULong(coef.Uint64()), nil
+		}
+		if !coef.IsInt64() {
+			return nil, errors.Errorf("long overflow")
+		}
+		return Long(coef.Int64()), nil
+	default:
+		return nil, errors.Errorf("invalid number type %d", t)
+	}
+}
+
+func (d *keyDecoder) readList() (Value, error) {
+	var values []Value
+	for {
+		if d.eof() {
+			return nil, d.errorf("unexpected end of key")
+		}
+		if d.buf[d.pos] == keyEnd {
+			d.pos++
+			break
+		}
+		val, err := d.next()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, val)
+	}
+	flavour, err := d.readByte()
+	if err != nil {
+		return nil, err
+	}
+	switch flavour {
+	case keySolidList:
+		if len(values) == 0 {
+			return EmptyList(), nil
+		}
+		return SolidList(values), nil
+	case keySparseList:
+		return SparseListOf(values), nil
+	default:
+		return nil, d.errorf("invalid list flavour %d", flavour)
+	}
+}
+
+func (d *keyDecoder) readMap() (Value, error) {
+	var entries []MapEntry
+	for {
+		b, err := d.readByte()
+		if err != nil {
+			return nil, err
+		}
+		if b == keyEnd {
+			break
+		}
+		if b != keyEntry {
+			return nil, d.errorf("invalid map entry 0x%02x", b)
+		}
+		key, err := d.readBytes()
+		if err != nil {
+			return nil, err
+		}
+		val, err := d.next()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, Entry(string(key), val))
+	}
+	return SortedMap(entries, true), nil
+}
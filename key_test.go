@@ -0,0 +1,140 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value_test
+
+import (
+	"bytes"
+	val "arpabet.pkg.is/value"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func testKeyValues() []val.Value {
+	big1, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+	return []val.Value{
+		nil,
+		val.False,
+		val.True,
+		val.Nan(),
+		val.Double(math.Inf(-1)),
+		val.BigInt(big1),
+		val.Long(math.MinInt64),
+		val.Long(-1000),
+		val.Double(-999.5),
+		val.Long(-1),
+		val.Decimal(decimal.RequireFromString("-0.001")),
+		val.Long(0),
+		val.Double(0),
+		val.Double(math.SmallestNonzeroFloat64),
+		val.Decimal(decimal.RequireFromString("0.1")),
+		val.Double(0.1),
+		val.Long(1),
+		val.Double(1.5),
+		val.Long(10),
+		val.Long(math.MaxInt64),
+		val.BigInt(new(big.Int).Neg(big1)),
+		val.Double(math.MaxFloat64),
+		val.Double(math.Inf(1)),
+		val.Utf8(""),
+		val.Utf8("a"),
+		val.Raw([]byte{'a', 0}, false),
+		val.Utf8("a\x00b"),
+		val.Utf8("ab"),
+		val.Raw([]byte{0xff}, false),
+		val.Timestamp(time.Unix(-10, 5)),
+		val.Timestamp(time.Unix(1600000000, 0)),
+		val.EmptyList(),
+		val.Tuple(nil),
+		val.Tuple(val.Long(1)),
+		val.SparseListOf([]val.Value{val.Long(1)}),
+		val.Tuple(val.Long(1), val.Utf8("x")),
+		val.Tuple(val.Long(2)),
+		val.EmptyMap(),
+		val.EmptyMap().Put("a", val.Long(1)),
+		val.EmptyMap().Put("a", val.Long(1)).Put("b", val.Long(0)),
+		val.EmptyMap().Put("a", val.Long(2)),
+		val.EmptyMap().Put("b", val.Long(0)),
+		val.Unknown([]byte{9, 0, 1}),
+	}
+}
+
+func TestKeyOrder(t *testing.T) {
+
+	values := testKeyValues()
+
+	for i, a := range values {
+		ka := val.EncodeKey(val.Tuple(a))
+		for j, b := range values {
+			kb := val.EncodeKey(val.Tuple(b))
+			expected := val.Compare(a, b)
+			actual := bytes.Compare(ka, kb)
+			if expected != 0 {
+				require.Equal(t, expected, actual, "%s vs %s", val.Jsonify(a), val.Jsonify(b))
+			}
+			if i < j {
+				require.True(t, actual < 0, "%s vs %s", val.Jsonify(a), val.Jsonify(b))
+			}
+		}
+	}
+
+}
+
+func TestKeyRoundTrip(t *testing.T) {
+
+	for _, v := range testKeyValues() {
+		tuple, err := val.DecodeKey(val.EncodeKey(val.Tuple(v)))
+		require.Nil(t, err)
+		require.Equal(t, 1, tuple.Len())
+		actual := tuple.GetAt(0)
+		require.Equal(t, 0, val.Compare(v, actual), "%s vs %s", val.Jsonify(v), val.Jsonify(actual))
+		if v != nil && v.Kind() == val.NUMBER {
+			require.Equal(t, v.(val.Number).Type(), actual.(val.Number).Type())
+		}
+	}
+
+	tuple, err := val.DecodeKey(nil)
+	require.Nil(t, err)
+	require.Equal(t, 0, tuple.Len())
+
+	_, err = val.DecodeKey([]byte{0x30, 'a'})
+	require.NotNil(t, err)
+	_, err = val.DecodeKey([]byte{0xee})
+	require.NotNil(t, err)
+
+}
+
+func TestKeyTuplePrefix(t *testing.T) {
+
+	user := val.EncodeKey(val.Tuple(val.Utf8("user")))
+	key := val.EncodeKey(val.Tuple(val.Utf8("user"), val.Long(42)))
+	other := val.EncodeKey(val.Tuple(val.Utf8("users"), val.Long(1)))
+
+	require.True(t, bytes.HasPrefix(key, user))
+	require.False(t, bytes.HasPrefix(other, user))
+	require.Equal(t, user, val.EncodeKey(val.Utf8("user")))
+
+	tuple, err := val.DecodeKey(key)
+	require.Nil(t, err)
+	require.True(t, val.Equal(val.Tuple(val.Utf8("user"), val.Long(42)), tuple))
+
+}
@@ -0,0 +1,341 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value
+
+import (
+	"bytes"
+	"crypto"
+	"hash"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+/**
+	Merkle hashing of the Value tree
+
+	Every List element and Map entry is hashed separately, then parent digest
+	combines the keys and child digests:
+
+		leaf   = H(0x00 || Pack(value))
+		list   = H(0x01 || PackList(n) || digest...)
+		sparse = H(0x02 || PackMap(n) || (PackLong(key) || digest)...)
+		map    = H(0x03 || PackMap(n) || (PackStr(key) || digest)...)
+*/
+
+const (
+	merkleLeaf byte = iota
+	merkleList
+	merkleSparseList
+	merkleMap
+)
+
+type MerkleNode struct {
+
+	/**
+		Map key or list index of the node in the parent, empty for root
+	*/
+
+	Key string
+
+	Digest []byte
+
+	/**
+		Child nodes of List or Map, nil for leaf
+	*/
+
+	Children []*MerkleNode
+}
+
+/**
+	Calculates Merkle root digest of the value
+*/
+
+func MerkleHash(val Value, hash crypto.Hash) ([]byte, error) {
+	if !hash.Available() {
+		return nil, ErrHashUnavailable
+	}
+	node, err := NewMerkleHasher(hash.New, false).Tree(val)
+	if err != nil {
+		return nil, err
+	}
+	return node.Digest, nil
+}
+
+/**
+	Builds Merkle tree of digests for the value
+*/
+
+func MerkleTree(val Value, hash crypto.Hash) (*MerkleNode, error) {
+	if !hash.Available() {
+		return nil, ErrHashUnavailable
+	}
+	return NewMerkleHasher(hash.New, false).Tree(val)
+}
+
+type merkleKey struct {
+	class reflect.Type
+	ptr   uintptr
+	len   int
+}
+
+type merkleMemo struct {
+	val  Value // holds the reference, so address can not be reused
+	node *MerkleNode
+}
+
+/**
+	Merkle hasher with optional memoization of persistent List and Map subtrees
+
+	Memoization covers only VectorList and BTreeMap, their nodes are never changed after creation,
+	therefore the same root node always has the same digest. Slice based lists and maps are hashed every time,
+	because with AllowFastAppends two values may share the backing array with different contents.
+	Memoized hasher keeps references on all hashed collections until Reset.
+*/
+
+type MerkleHasher struct {
+	newHash func() hash.Hash
+	memoize bool
+	cache   sync.Map // merkleKey -> *merkleMemo
+}
+
+func NewMerkleHasher(newHash func() hash.Hash, memoize bool) *MerkleHasher {
+	return &MerkleHasher{newHash: newHash, memoize: memoize}
+}
+
+/**
+	Drops all memoized digests
+*/
+
+func (m *MerkleHasher) Reset() {
+	m.cache.Range(func(key, _ interface{}) bool {
+		m.cache.Delete(key)
+		return true
+	})
+}
+
+func (m *MerkleHasher) Hash(val Value) ([]byte, error) {
+	node, err := m.Tree(val)
+	if err != nil {
+		return nil, err
+	}
+	return node.Digest, nil
+}
+
+func (m *MerkleHasher) Tree(val Value) (*MerkleNode, error) {
+	return m.tree("", val)
+}
+
+func (m *MerkleHasher) tree(key string, val Value) (*MerkleNode, error) {
+
+	if val == nil || (val.Kind() != LIST && val.Kind() != MAP) {
+		digest, err := m.leaf(val)
+		if err != nil {
+			return nil, err
+		}
+		return &MerkleNode{Key: key, Digest: digest}, nil
+	}
+
+	mk, ok := m.memoKey(val)
+	if ok {
+		if memo, ok := m.cache.Load(mk); ok {
+			node := memo.(*merkleMemo).node
+			if node.Key == key {
+				return node, nil
+			}
+			return &MerkleNode{Key: key, Digest: node.Digest, Children: node.Children}, nil
+		}
+	}
+
+	var node *MerkleNode
+	var err error
+	switch v := val.(type) {
+	case sparseListValue:
+		node, err = m.sparseList(key, v)
+	case List:
+		node, err = m.list(key, v)
+	case Map:
+		node, err = m.hashMap(key, v)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if ok {
+		m.cache.Store(mk, &merkleMemo{val: val, node: node})
+	}
+	return node, nil
+}
+
+func (m *MerkleHasher) memoKey(val Value) (merkleKey, bool) {
+	if !m.memoize {
+		return merkleKey{}, false
+	}
+	switch v := val.(type) {
+	case vectorListValue:
+		if v.root != nil {
+			return merkleKey{vectorListValueClass, reflect.ValueOf(v.root).Pointer(), 0}, true
+		}
+	case btreeMapValue:
+		if v.root != nil {
+			return merkleKey{btreeMapValueClass, reflect.ValueOf(v.root).Pointer(), v.size}, true
+		}
+	}
+	return merkleKey{}, false
+}
+
+func (m *MerkleHasher) leaf(val Value) ([]byte, error) {
+	h := m.newHash()
+	h.Write([]byte{merkleLeaf})
+	return HashWith(val, h)
+}
+
+func (m *MerkleHasher) list(key string, list List) (*MerkleNode, error) {
+	values := list.Values()
+	node := &MerkleNode{Key: key, Children: make([]*MerkleNode, len(values))}
+	h := m.newHash()
+	h.Write([]byte{merkleList})
+	p := MessagePacker(h)
+	p.PackList(len(values))
+	for i, val := range values {
+		child, err := m.tree(strconv.Itoa(i), val)
+		if err != nil {
+			return nil, err
+		}
+		node.Children[i] = child
+		p.PackRaw(child.Digest)
+	}
+	if p.Error() != nil {
+		return nil, p.Error()
+	}
+	node.Digest = h.Sum(nil)
+	return node, nil
+}
+
+func (m *MerkleHasher) sparseList(key string, list sparseListValue) (*MerkleNode, error) {
+	node := &MerkleNode{Key: key, Children: make([]*MerkleNode, len(list))}
+	h := m.newHash()
+	h.Write([]byte{merkleSparseList})
+	p := MessagePacker(h)
+	p.PackMap(len(list))
+	for i, item := range list {
+		child, err := m.tree(strconv.Itoa(item.Key()), item.Value())
+		if err != nil {
+			return nil, err
+		}
+		node.Children[i] = child
+		p.PackLong(int64(item.Key()))
+		p.PackRaw(child.Digest)
+	}
+	if p.Error() != nil {
+		return nil, p.Error()
+	}
+	node.Digest = h.Sum(nil)
+	return node, nil
+}
+
+func (m *MerkleHasher) hashMap(key string, tbl Map) (*MerkleNode, error) {
+	entries := tbl.Entries()
+	node := &MerkleNode{Key: key, Children: make([]*MerkleNode, len(entries))}
+	h := m.newHash()
+	h.Write([]byte{merkleMap})
+	p := MessagePacker(h)
+	p.PackMap(len(entries))
+	for i, entry := range entries {
+		child, err := m.tree(entry.Key(), entry.Value())
+		if err != nil {
+			return nil, err
+		}
+		node.Children[i] = child
+		p.PackStr(entry.Key())
+		p.PackRaw(child.Digest)
+	}
+	if p.Error() != nil {
+		return nil, p.Error()
+	}
+	node.Digest = h.Sum(nil)
+	return node, nil
+}
+
+/**
+	Gets child node by the key or nil
+*/
+
+func (n *MerkleNode) Child(key string) *MerkleNode {
+	for _, child := range n.Children {
+		if child.Key == key {
+			return child
+		}
+	}
+	return nil
+}
+
+/**
+	Finds paths of the smallest subtrees that differ between two Merkle trees
+
+	Paths are JSON Pointers (RFC 6901), descends only into subtrees with different digests,
+	so replicas can exchange trees level by level
+*/
+
+func MerkleDiff(left, right *MerkleNode) []string {
+	var paths []string
+	merkleDiff("", left, right, &paths)
+	return paths
+}
+
+func merkleDiff(path string, left, right *MerkleNode, paths *[]string) {
+	if left == nil || right == nil {
+		if left != right {
+			*paths = append(*paths, path)
+		}
+		return
+	}
+	if bytes.Equal(left.Digest, right.Digest) {
+		return
+	}
+	if left.Children == nil || right.Children == nil {
+		*paths = append(*paths, path)
+		return
+	}
+	before := len(*paths)
+	leftIndex := indexMerkleChildren(left)
+	rightIndex := indexMerkleChildren(right)
+	for _, l := range left.Children {
+		merkleDiff(path+"/"+escapePointerToken(l.Key), l, rightIndex[l.Key], paths)
+	}
+	for _, r := range right.Children {
+		if _, ok := leftIndex[r.Key]; !ok {
+			*paths = append(*paths, path+"/"+escapePointerToken(r.Key))
+		}
+	}
+	if len(*paths) == before {
+		// same keys and digests, but different type of collection
+		*paths = append(*paths, path)
+	}
+}
+
+func indexMerkleChildren(n *MerkleNode) map[string]*MerkleNode {
+	index := make(map[string]*MerkleNode, len(n.Children))
+	for _, child := range n.Children {
+		if _, ok := index[child.Key]; !ok {
+			index[child.Key] = child
+		}
+	}
+	return index
+}
@@ -0,0 +1,143 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value_test
+
+import (
+	"crypto"
+	"crypto/sha256"
+	val "arpabet.pkg.is/value"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMerkleHash(t *testing.T) {
+
+	m := testCreateMap()
+
+	a, err := val.MerkleHash(m, crypto.SHA256)
+	require.Nil(t, err)
+	require.Equal(t, sha256.Size, len(a))
+
+	b, err := val.MerkleHash(testCreateMap(), crypto.SHA256)
+	require.Nil(t, err)
+	require.Equal(t, a, b)
+
+	c, err := val.MerkleHash(m.Put("name", val.Utf8("other")), crypto.SHA256)
+	require.Nil(t, err)
+	require.NotEqual(t, a, c)
+
+	d, err := val.MerkleHash(val.Tuple(val.Long(1)), crypto.SHA256)
+	require.Nil(t, err)
+	e, err := val.MerkleHash(val.SparseListOf([]val.Value{val.Long(1)}), crypto.SHA256)
+	require.Nil(t, err)
+	require.NotEqual(t, d, e)
+
+}
+
+func TestMerkleDiff(t *testing.T) {
+
+	left := testCreateMap()
+	right := left.Put("list", left.GetList("list").PutAt(1, val.Long(456))).Put("new", val.True).Remove("5")
+
+	l, err := val.MerkleTree(left, crypto.SHA256)
+	require.Nil(t, err)
+	r, err := val.MerkleTree(right, crypto.SHA256)
+	require.Nil(t, err)
+
+	require.Equal(t, []string{"/5", "/list/1", "/new"}, val.MerkleDiff(l, r))
+	require.Nil(t, val.MerkleDiff(l, l))
+
+	require.NotNil(t, l.Child("list"))
+	require.Equal(t, 5, len(l.Child("list").Children))
+
+}
+
+func TestMerkleMemoize(t *testing.T) {
+
+	sub := testCreateMap()
+	m := val.EmptyMap().Put("a", sub).Put("b", sub)
+
+	expected, err := val.MerkleHash(m, crypto.SHA256)
+	require.Nil(t, err)
+
+	h := val.NewMerkleHasher(sha256.New, true)
+
+	actual, err := h.Hash(m)
+	require.Nil(t, err)
+	require.Equal(t, expected, actual)
+
+	tree, err := h.Tree(m)
+	require.Nil(t, err)
+	require.Equal(t, expected, tree.Digest)
+	require.Equal(t, "a", tree.Children[0].Key)
+	require.Equal(t, "b", tree.Children[1].Key)
+	require.Equal(t, tree.Children[0].Digest, tree.Children[1].Digest)
+
+	h.Reset()
+	actual, err = h.Hash(m)
+	require.Nil(t, err)
+	require.Equal(t, expected, actual)
+
+}
+
+func TestMerkleMemoizeSharedArray(t *testing.T) {
+
+	values := make([]val.Value, 3, 8)
+	for i := range values {
+		values[i] = val.Long(int64(i))
+	}
+	base := val.SolidList(values)
+
+	h := val.NewMerkleHasher(sha256.New, true)
+
+	first := base.Append(val.Long(4))
+	_, err := h.Hash(val.EmptyMap().Put("list", first))
+	require.Nil(t, err)
+
+	second := base.Append(val.Long(5))
+	actual, err := h.Hash(val.EmptyMap().Put("list", second))
+	require.Nil(t, err)
+
+	expected, err := val.MerkleHash(val.EmptyMap().Put("list", second), crypto.SHA256)
+	require.Nil(t, err)
+	require.Equal(t, expected, actual)
+
+}
+
+func TestMerkleMemoizePersistent(t *testing.T) {
+
+	sub := val.VectorList([]val.Value{val.Long(1), val.Long(2), val.Long(3)})
+	m := val.EmptyBTreeMap().Put("a", sub).Put("b", sub.Append(val.Long(4)))
+
+	h := val.NewMerkleHasher(sha256.New, true)
+
+	for _, v := range []val.Value{m, m.Put("a", sub.PutAt(0, val.Long(5))), m.Remove("b"), sub} {
+		expected, err := val.MerkleHash(v, crypto.SHA256)
+		require.Nil(t, err)
+
+		actual, err := h.Hash(v)
+		require.Nil(t, err)
+		require.Equal(t, expected, actual)
+
+		actual, err = h.Hash(v)
+		require.Nil(t, err)
+		require.Equal(t, expected, actual)
+	}
+
+}
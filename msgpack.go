@@ -93,6 +93,7 @@ const (
 	mpNegFixIntPrefix 	byte = 0xe0
 
 	defWriteBufSize 	= 16
+	defStrBufSize 		= 128
 	defReadBufSize 		= 24
 
 	mpCodeMin 			= mpNil
@@ -116,13 +117,13 @@ var (
 		ExtHeader,  // mpExt16		0xc8
 		ExtHeader,  // mpExt32		0xc9
 
-		DoubleToken,  // mpFloat32	0xca
+		FloatToken,  // mpFloat32	0xca
 		DoubleToken,  // mpFloat64	0xcb
 
 		LongToken,  // mpUint8		0xcc
 		LongToken,  // mpUint16		0xcd
 		LongToken,  // mpUint32		0xce
-		LongToken,  // mpUint64  	0xcf
+		ULongToken,  // mpUint64  	0xcf
 
 		LongToken,  // mpInt8 		0xd0
 		LongToken,  // mpInt16	 	0xd1
@@ -200,48 +201,71 @@ var (
 type messagePacker struct {
 	m   messageWriter
 	w   io.Writer
+	sw  io.StringWriter  // can be null
+	str [defStrBufSize]byte
 	err error
 }
 
 func MessagePacker(w io.Writer) *messagePacker {
-	return &messagePacker{w: w}
+	sw, _ := w.(io.StringWriter)
+	return &messagePacker{w: w, sw: sw}
 }
 
-func (p messagePacker) PackNil()  {
+func (p *messagePacker) PackNil()  {
 	if p.err == nil {
 		_, p.err = p.w.Write(p.m.WriteNil())
 	}
 }
 
-func (p messagePacker) PackBool(val bool) {
+func (p *messagePacker) PackBool(val bool) {
 	if p.err == nil {
 		_, p.err = p.w.Write(p.m.WriteBool(val))
 	}
 }
 
-func (p messagePacker) PackLong(val int64) {
+func (p *messagePacker) PackLong(val int64) {
 	if p.err == nil {
 		_, p.err = p.w.Write(p.m.WriteLong(val))
 	}
 }
 
-func (p messagePacker) PackDouble(val float64) {
+func (p *messagePacker) PackULong(val uint64) {
+	if p.err == nil {
+		_, p.err = p.w.Write(p.m.WriteULong(val))
+	}
+}
+
+func (p *messagePacker) PackDouble(val float64) {
 	if p.err == nil {
 		_, p.err = p.w.Write(p.m.WriteDouble(val))
 	}
 }
 
-func (p messagePacker) PackStr(str string) {
-	b := []byte(str)
+func (p *messagePacker) PackFloat(val float32) {
 	if p.err == nil {
-		_, p.err = p.w.Write(p.m.WriteStrHeader(len(b)))
+		_, p.err = p.w.Write(p.m.WriteFloat(val))
 	}
+}
+
+func (p *messagePacker) PackStr(str string) {
 	if p.err == nil {
-		_, p.err = p.w.Write(b)
+		_, p.err = p.w.Write(p.m.WriteStrHeader(len(str)))
+	}
+	if p.sw != nil {
+		if p.err == nil {
+			_, p.err = p.sw.WriteString(str)
+		}
+		return
+	}
+	// copy by chunks to avoid memory allocation
+	for len(str) > 0 && p.err == nil {
+		n := copy(p.str[:], str)
+		_, p.err = p.w.Write(p.str[:n])
+		str = str[n:]
 	}
 }
 
-func (p messagePacker) PackBin(b []byte) {
+func (p *messagePacker) PackBin(b []byte) {
 	if p.err == nil {
 		_, p.err = p.w.Write(p.m.WriteBinHeader(len(b)))
 	}
@@ -259,7 +283,7 @@ func (p *messagePacker) PackExt(xtag Ext, data []byte) {
 	}
 }
 
-func (p messagePacker) PackList(size int) {
+func (p *messagePacker) PackList(size int) {
 	if size < 0 {
 		size = 0
 	}
@@ -268,7 +292,7 @@ func (p messagePacker) PackList(size int) {
 	}
 }
 
-func (p messagePacker) PackMap(size int) {
+func (p *messagePacker) PackMap(size int) {
 	if size < 0 {
 		size = 0
 	}
@@ -277,13 +301,13 @@ func (p messagePacker) PackMap(size int) {
 	}
 }
 
-func (p messagePacker) PackRaw(b []byte) {
+func (p *messagePacker) PackRaw(b []byte) {
 	if p.err == nil {
 		_, p.err = p.w.Write(b)
 	}
 }
 
-func (p messagePacker) Error() error {
+func (p *messagePacker) Error() error {
 	return p.err
 }
 
@@ -291,11 +315,11 @@ type messageWriter struct {
 	buf 	[defWriteBufSize]byte
 }
 
-func (p messageWriter) WriteNil() []byte {
+func (p *messageWriter) WriteNil() []byte {
 	return mpNilBin
 }
 
-func (p messageWriter) WriteBool(val bool) []byte {
+func (p *messageWriter) WriteBool(val bool) []byte {
 	if val {
 		return mpTrueBin
 	} else {
@@ -303,11 +327,11 @@ func (p messageWriter) WriteBool(val bool) []byte {
 	}
 }
 
-func (p messageWriter) WriteLong(val int64) []byte {
+func (p *messageWriter) WriteLong(val int64) []byte {
 
 	switch {
 		case val >= 0:
-			return p.writeVULong(uint64(val))
+			return p.WriteULong(uint64(val))
 		case val >= -32:
 			p.buf[0] = byte(val)
 			return p.buf[:1]
@@ -331,7 +355,7 @@ func (p messageWriter) WriteLong(val int64) []byte {
 
 }
 
-func (p messageWriter) writeVULong(val uint64) []byte {
+func (p *messageWriter) WriteULong(val uint64) []byte {
 	switch {
 	case val <= math.MaxInt8:
 		p.buf[0] = byte(val)
@@ -355,13 +379,19 @@ func (p messageWriter) writeVULong(val uint64) []byte {
 	}
 }
 
-func (p messageWriter) WriteDouble(val float64) []byte {
+func (p *messageWriter) WriteDouble(val float64) []byte {
 	p.buf[0] = mpFloat64
 	binary.BigEndian.PutUint64(p.buf[1:9], math.Float64bits(val))
 	return p.buf[:9]
 }
 
-func (p messageWriter) WriteBinHeader(len int) []byte {
+func (p *messageWriter) WriteFloat(val float32) []byte {
+	p.buf[0] = mpFloat32
+	binary.BigEndian.PutUint32(p.buf[1:5], math.Float32bits(val))
+	return p.buf[:5]
+}
+
+func (p *messageWriter) WriteBinHeader(len int) []byte {
 	switch {
 	case len <= math.MaxUint8:
 		p.buf[0] = mpBin8
@@ -378,7 +408,7 @@ func (p messageWriter) WriteBinHeader(len int) []byte {
 	}
 }
 
-func (p messageWriter) WriteStrHeader(len int) []byte {
+func (p *messageWriter) WriteStrHeader(len int) []byte {
 	switch {
 	case len < 32:
 		p.buf[0] = mpFixStrPrefix | byte(len)
@@ -398,7 +428,7 @@ func (p messageWriter) WriteStrHeader(len int) []byte {
 	}
 }
 
-func (p messageWriter) WriteExtHeader(len int, xtag byte) []byte {
+func (p *messageWriter) WriteExtHeader(len int, xtag byte) []byte {
 	switch len {
 	case 1:
 		p.buf[0] = mpFixExt1
@@ -440,7 +470,7 @@ func (p messageWriter) WriteExtHeader(len int, xtag byte) []byte {
 	}
 }
 
-func (p messageWriter) WriteArrayHeader(len int) []byte {
+func (p *messageWriter) WriteArrayHeader(len int) []byte {
 	switch {
 	case len < 16:
 		p.buf[0] = mpFixArrayPrefix | byte(len)
@@ -450,13 +480,13 @@ func (p messageWriter) WriteArrayHeader(len int) []byte {
 		binary.BigEndian.PutUint16(p.buf[1:3], uint16(len))
 		return p.buf[:3]
 	default:
-		p.buf[0] = mpArray16
+		p.buf[0] = mpArray32
 		binary.BigEndian.PutUint32(p.buf[1:5], uint32(len))
 		return p.buf[:5]
 	}
 }
 
-func (p messageWriter) WriteMapHeader(len int) []byte {
+func (p *messageWriter) WriteMapHeader(len int) []byte {
 	switch {
 	case len < 16:
 		p.buf[0] = mpFixMapPrefix | byte(len)
@@ -529,6 +559,18 @@ func (r *messageParser) ParseLong(b []byte) int64 {
 	}
 }
 
+func (r *messageParser) ParseULong(b []byte) uint64 {
+
+	code := b[0]
+
+	switch code {
+	case mpUint64:
+		return binary.BigEndian.Uint64(b[1:])
+	default:
+		return uint64(r.ParseLong(b))
+	}
+}
+
 func (r *messageParser) ParseDouble(b []byte) float64 {
 
 	code := b[0]
@@ -546,6 +588,20 @@ func (r *messageParser) ParseDouble(b []byte) float64 {
 	}
 }
 
+func (r *messageParser) ParseFloat(b []byte) float32 {
+
+	code := b[0]
+
+	switch code {
+	case mpFloat32:
+		val32 := binary.BigEndian.Uint32(b[1:])
+		return math.Float32frombits(val32)
+	default:
+		r.err = errors.Errorf("float: invalid code %v", code)
+		return 0
+	}
+}
+
 func (r *messageParser) ParseBin(b []byte) int {
 
 	code := b[0]
@@ -0,0 +1,84 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value
+
+import (
+	"reflect"
+	"strings"
+)
+
+/**
+	Explicit null value, packs as MessagePack nil and prints as JSON null
+
+	Null is equal to Null and to Go nil, use IsNull to check both.
+*/
+
+type nullValue struct{}
+
+var theNull = nullValue{}
+var nullValueClass = reflect.TypeOf(theNull)
+
+func Null() Value {
+	return theNull
+}
+
+/**
+	Checks if the value is Go nil or Null
+*/
+
+func IsNull(val Value) bool {
+	return val == nil || val.Kind() == NULL
+}
+
+func (n nullValue) Kind() Kind {
+	return NULL
+}
+
+func (n nullValue) Class() reflect.Type {
+	return nullValueClass
+}
+
+func (n nullValue) Object() interface{} {
+	return nil
+}
+
+func (n nullValue) String() string {
+	return "null"
+}
+
+func (n nullValue) Pack(p Packer) {
+	p.PackNil()
+}
+
+func (n nullValue) PrintJSON(out *strings.Builder) {
+	out.WriteString("null")
+}
+
+func (n nullValue) MarshalJSON() ([]byte, error) {
+	return []byte("null"), nil
+}
+
+func (n nullValue) MarshalBinary() ([]byte, error) {
+	var m messageWriter
+	return m.WriteNil(), nil
+}
+
+func (n nullValue) Equal(val Value) bool {
+	return IsNull(val)
+}
@@ -0,0 +1,94 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value_test
+
+import (
+	val "arpabet.pkg.is/value"
+	"github.com/stretchr/testify/require"
+	"encoding/hex"
+	"testing"
+)
+
+
+func TestNull(t *testing.T) {
+
+	n := val.Null()
+	require.Equal(t, val.NULL, n.Kind())
+	require.Equal(t, "NULL", n.Kind().String())
+	require.Nil(t, n.Object())
+	require.Equal(t, "null", n.String())
+	require.Equal(t, "null", val.Jsonify(n))
+	require.Equal(t, "c0", val.Hex(n))
+
+	bin, _ := n.MarshalBinary()
+	require.Equal(t, []byte{0xc0}, bin)
+
+	require.True(t, n.Equal(val.Null()))
+	require.True(t, n.Equal(nil))
+	require.True(t, val.Equal(nil, n))
+	require.False(t, n.Equal(val.Long(0)))
+	require.False(t, val.Long(0).Equal(n))
+
+	require.True(t, val.IsNull(nil))
+	require.True(t, val.IsNull(n))
+	require.False(t, val.IsNull(val.False))
+
+	require.Equal(t, 0, val.Compare(n, nil))
+	require.Equal(t, -1, val.Compare(n, val.False))
+	require.Equal(t, val.EncodeKey(nil), val.EncodeKey(n))
+
+}
+
+func TestUnpackNull(t *testing.T) {
+
+	list := val.SolidList([]val.Value{val.Long(1), val.Null(), val.Utf8("a")})
+	mp, err := val.Pack(list)
+	require.NoError(t, err)
+	require.Equal(t, "9301c0a161", hex.EncodeToString(mp))
+
+	c, err := val.Unpack(mp, false)
+	require.NoError(t, err)
+	require.Nil(t, c.(val.List).GetAt(1))
+	require.True(t, list.Equal(c))
+
+	c, err = val.Unpack(mp, false, val.WithNull())
+	require.NoError(t, err)
+	for _, item := range c.(val.List).Values() {
+		require.NotEqual(t, val.INVALID, item.Kind())
+	}
+	require.Equal(t, val.NULL, c.(val.List).GetAt(1).Kind())
+	require.Equal(t, "[1,null,\"a\"]", val.Jsonify(c))
+
+	m := val.EmptyMap().Put("a", val.Null()).Put("b", val.Long(2))
+	mp, err = val.Pack(m)
+	require.NoError(t, err)
+
+	c, err = val.Unpack(mp, false, val.WithNull())
+	require.NoError(t, err)
+	for _, item := range c.(val.Map).Values() {
+		require.NotNil(t, item)
+	}
+	require.Nil(t, c.(val.Map).GetNumber("a"))
+	require.Nil(t, c.(val.Map).GetString("a"))
+
+	c, err = val.Unpack([]byte{0xc0}, false, val.WithNull())
+	require.NoError(t, err)
+	require.Equal(t, val.Null(), c)
+
+}
@@ -40,8 +40,50 @@ var DecimalExpDelimStr = "x"
 
 var PrecisionLevel = 0.00001
 
+/**
+	JSON representation of BIGINT and DECIMAL numbers, see JsonifyWith and PrintJSONStyle
+*/
+
+type NumberStyle int
+
+const (
+
+	/**
+		Quoted hex string "0x..." with DecimalExpDelim before the exponent, exact and understood by ParseJSON
+	*/
+
+	HexNumbers NumberStyle = iota
+
+	/**
+		Plain decimal JSON number, readable by any consumer, but may lose precision there
+	*/
+
+	PlainNumbers
+
+	/**
+		Quoted decimal string, exact and readable, parsed back as a string by ParseJSON
+	*/
+
+	QuotedNumbers
+)
+
+func (s NumberStyle) String() string {
+	switch s {
+	case HexNumbers:
+		return "hex"
+	case PlainNumbers:
+		return "plain"
+	case QuotedNumbers:
+		return "quoted"
+	default:
+		return "unknown"
+	}
+}
+
 type longNumber int64
 type doubleNumber float64
+type ulongNumber uint64
+type floatNumber float32
 
 type bigIntNumber struct {
 	*big.Int
@@ -53,6 +95,8 @@ var longNumberClass = reflect.TypeOf((*longNumber)(nil)).Elem()
 var doubleNumberClass = reflect.TypeOf((*doubleNumber)(nil)).Elem()
 var bigIntNumberClass = reflect.TypeOf((*bigIntNumber)(nil)).Elem()
 var decimalNumberClass =  reflect.TypeOf((*decimalNumber)(nil)).Elem()
+var ulongNumberClass = reflect.TypeOf((*ulongNumber)(nil)).Elem()
+var floatNumberClass = reflect.TypeOf((*floatNumber)(nil)).Elem()
 
 func Long(val int64) Number {
 	return longNumber(val)
@@ -73,6 +117,25 @@ func Decimal(dec decimal.Decimal) Number {
 	return decimalNumber(dec)
 }
 
+func ULong(val uint64) Number {
+	return ulongNumber(val)
+}
+
+/**
+	Unsigned number as LONG if it fits, otherwise as ULONG, the same way as MessagePack uint64 is decoded
+*/
+
+func Unsigned(val uint64) Number {
+	if val > math.MaxInt64 {
+		return ulongNumber(val)
+	}
+	return longNumber(int64(val))
+}
+
+func Float(val float32) Number {
+	return floatNumber(val)
+}
+
 func Nan() Number {
 	return doubleNumber(math.NaN())
 }
@@ -87,32 +150,48 @@ func ParseNumber(str string) Number {
 		return Nan()
 	}
 
-	if strings.IndexByte(str, '.') != -1 {
-		double, err := strconv.ParseFloat(str, 64)
-		if err == nil {
-			return Double(double)
-		}
-		return Nan()
-	}
-
 	if hasHexPrefix(str) {
 		if val, err := parseHexNumber(str); err == nil {
 			return val
 		}
 	}
 
-	long, err := strconv.ParseInt(str, 10, 64)
-	if err == nil {
-		return Long(long)
-	} else {
-		double, err := strconv.ParseFloat(str, 64)
-		if err == nil {
-			return Double(double)
+	if strings.IndexAny(str, ".eE") == -1 {
+		if long, err := strconv.ParseInt(str, 10, 64); err == nil {
+			return Long(long)
+		}
+		if ulong, err := strconv.ParseUint(str, 10, 64); err == nil {
+			return ULong(ulong)
+		}
+		if bigInt, ok := new(big.Int).SetString(str, 10); ok {
+			return BigInt(bigInt)
 		}
 	}
 
-	return Nan()
+	return parseDecimalNumber(str)
+
+}
 
+/**
+	Parses decimal literal with the optional fraction and exponent,
+	gives DOUBLE if the double keeps the literal exactly in the shortest form, otherwise exact DECIMAL
+*/
+
+func parseDecimalNumber(str string) Number {
+	double, err := strconv.ParseFloat(str, 64)
+	dec, decErr := decimal.NewFromString(str)
+	if decErr != nil {
+		// NaN and Inf literals
+		if err == nil {
+			return Double(double)
+		}
+		return Nan()
+	}
+	if err == nil && decimal.NewFromFloat(double).Equal(dec) {
+		return Double(double)
+	}
+	// precision loss, overflow or underflow
+	return Decimal(dec)
 }
 
 func parseHexNumber(s string) (Number, error) {
@@ -181,6 +260,14 @@ func (n decimalNumber) Type() NumberType {
 	return DECIMAL
 }
 
+func (n ulongNumber) Type() NumberType {
+	return ULONG
+}
+
+func (n floatNumber) Type() NumberType {
+	return FLOAT
+}
+
 func (n longNumber) Kind() Kind {
 	return NUMBER
 }
@@ -197,6 +284,14 @@ func (n decimalNumber) Kind() Kind {
 	return NUMBER
 }
 
+func (n ulongNumber) Kind() Kind {
+	return NUMBER
+}
+
+func (n floatNumber) Kind() Kind {
+	return NUMBER
+}
+
 func (n longNumber) Class() reflect.Type {
 	return longNumberClass
 }
@@ -213,6 +308,14 @@ func (n decimalNumber) Class() reflect.Type {
 	return decimalNumberClass
 }
 
+func (n ulongNumber) Class() reflect.Type {
+	return ulongNumberClass
+}
+
+func (n floatNumber) Class() reflect.Type {
+	return floatNumberClass
+}
+
 func (n longNumber) String() string {
 	return strconv.FormatInt(int64(n), 10)
 }
@@ -234,6 +337,19 @@ func (n decimalNumber) String() string {
 	return formatDecimal(decimal.Decimal(n))
 }
 
+func (n ulongNumber) String() string {
+	return strconv.FormatUint(uint64(n), 10)
+}
+
+func (n floatNumber) String() string {
+	f := float32(n)
+	if f != f {
+		return "NaN"
+	} else {
+		return strconv.FormatFloat(float64(f), 'f', -1, 32)
+	}
+}
+
 func formatBigInt(val *big.Int) string {
 	s := hex.EncodeToString(val.Bytes())
 	if s == "" {
@@ -263,6 +379,17 @@ func formatDecimal(dec decimal.Decimal) string {
 	}
 }
 
+/**
+	Decimal notation keeping the scale of the number, 1.50 stays 1.50
+*/
+
+func formatPlainDecimal(dec decimal.Decimal) string {
+	if exp := dec.Exponent(); exp < 0 {
+		return dec.StringFixed(-exp)
+	}
+	return dec.String()
+}
+
 func formatExp(exp int32) string {
 	if exp == 0 {
 		return ""
@@ -324,6 +451,14 @@ func (n decimalNumber) Object() interface{} {
 	return decimal.Decimal(n)
 }
 
+func (n ulongNumber) Object() interface{} {
+	return uint64(n)
+}
+
+func (n floatNumber) Object() interface{} {
+	return float32(n)
+}
+
 func (n longNumber) Pack(p Packer) {
 	p.PackLong(int64(n))
 }
@@ -343,6 +478,14 @@ func (n decimalNumber) Pack(p Packer) {
 	p.PackExt(DecimalExt, b)
 }
 
+func (n ulongNumber) Pack(p Packer) {
+	p.PackULong(uint64(n))
+}
+
+func (n floatNumber) Pack(p Packer) {
+	p.PackFloat(float32(n))
+}
+
 func UnpackBigInt(data []byte) (*big.Int, error) {
 	x := new(big.Int)
 	err := x.GobDecode(data)
@@ -361,7 +504,7 @@ func (n longNumber) PrintJSON(out *strings.Builder) {
 
 func (n doubleNumber) PrintJSON(out *strings.Builder) {
 	d := float64(n)
-	if math.IsNaN(d) {
+	if math.IsNaN(d) || math.IsInf(d, 0) {
 		out.WriteString("null")
 	} else {
 		out.WriteString(strconv.FormatFloat(d, 'f', -1, 64))
@@ -369,15 +512,54 @@ func (n doubleNumber) PrintJSON(out *strings.Builder) {
 }
 
 func (n bigIntNumber) PrintJSON(out *strings.Builder) {
-	out.WriteRune(jsonQuote)
-	out.WriteString(formatBigInt(n.Int))
-	out.WriteRune(jsonQuote)
+	n.printJSONStyle(out, HexNumbers)
+}
+
+func (n bigIntNumber) printJSONStyle(out *strings.Builder, style NumberStyle) {
+	switch style {
+	case PlainNumbers:
+		out.WriteString(n.Int.String())
+	case QuotedNumbers:
+		out.WriteRune(jsonQuote)
+		out.WriteString(n.Int.String())
+		out.WriteRune(jsonQuote)
+	default:
+		out.WriteRune(jsonQuote)
+		out.WriteString(formatBigInt(n.Int))
+		out.WriteRune(jsonQuote)
+	}
 }
 
 func (n decimalNumber) PrintJSON(out *strings.Builder) {
-	out.WriteRune(jsonQuote)
-	out.WriteString(formatDecimal(decimal.Decimal(n)))
-	out.WriteRune(jsonQuote)
+	n.printJSONStyle(out, HexNumbers)
+}
+
+func (n decimalNumber) printJSONStyle(out *strings.Builder, style NumberStyle) {
+	switch style {
+	case PlainNumbers:
+		out.WriteString(formatPlainDecimal(decimal.Decimal(n)))
+	case QuotedNumbers:
+		out.WriteRune(jsonQuote)
+		out.WriteString(formatPlainDecimal(decimal.Decimal(n)))
+		out.WriteRune(jsonQuote)
+	default:
+		out.WriteRune(jsonQuote)
+		out.WriteString(formatDecimal(decimal.Decimal(n)))
+		out.WriteRune(jsonQuote)
+	}
+}
+
+func (n ulongNumber) PrintJSON(out *strings.Builder) {
+	out.WriteString(n.String())
+}
+
+func (n floatNumber) PrintJSON(out *strings.Builder) {
+	f := float32(n)
+	if f != f || math.IsInf(float64(f), 0) {
+		out.WriteString("null")
+	} else {
+		out.WriteString(strconv.FormatFloat(float64(f), 'f', -1, 32))
+	}
 }
 
 func (n longNumber) MarshalJSON() ([]byte, error) {
@@ -394,13 +576,28 @@ func (n doubleNumber) MarshalJSON() ([]byte, error) {
 }
 
 func (n bigIntNumber) MarshalJSON() ([]byte, error) {
-	s := formatBigInt(n.Int)
-	return []byte(strconv.Quote(s)), nil
+	var out strings.Builder
+	n.PrintJSON(&out)
+	return []byte(out.String()), nil
 }
 
 func (n decimalNumber) MarshalJSON() ([]byte, error) {
-	s := formatDecimal(decimal.Decimal(n))
-	return []byte(strconv.Quote(s)), nil
+	var out strings.Builder
+	n.PrintJSON(&out)
+	return []byte(out.String()), nil
+}
+
+func (n ulongNumber) MarshalJSON() ([]byte, error) {
+	return []byte(n.String()), nil
+}
+
+func (n floatNumber) MarshalJSON() ([]byte, error) {
+	f := float32(n)
+	if f != f {
+		return []byte("null"), nil
+	} else {
+		return []byte(strconv.FormatFloat(float64(f), 'f', -1, 32)), nil
+	}
 }
 
 func (n longNumber) MarshalBinary() ([]byte, error) {
@@ -427,6 +624,16 @@ func (n decimalNumber) MarshalBinary() ([]byte, error) {
 	return buf.Bytes(), p.Error()
 }
 
+func (n ulongNumber) MarshalBinary() ([]byte, error) {
+	m := new(messageWriter) // must be in heap
+	return m.WriteULong(uint64(n)), nil
+}
+
+func (n floatNumber) MarshalBinary() ([]byte, error) {
+	m := new(messageWriter) // must be in heap
+	return m.WriteFloat(float32(n)), nil
+}
+
 func (n longNumber) IsNaN() bool {
 	return false
 }
@@ -444,6 +651,14 @@ func (n decimalNumber) IsNaN() bool {
 	return false
 }
 
+func (n ulongNumber) IsNaN() bool {
+	return false
+}
+
+func (n floatNumber) IsNaN() bool {
+	return n != n
+}
+
 func (n longNumber) Long() int64 {
 	return int64(n)
 }
@@ -465,6 +680,43 @@ func (n decimalNumber) Long() int64 {
 	return decimal.Decimal(n).IntPart()
 }
 
+func (n ulongNumber) Long() int64 {
+	return int64(n)
+}
+
+func (n floatNumber) Long() int64 {
+	return doubleNumber(n).Long()
+}
+
+func (n longNumber) Uint64() uint64 {
+	return uint64(n)
+}
+
+func (n doubleNumber) Uint64() uint64 {
+	d := float64(n)
+	if math.IsNaN(d) {
+		return 0
+	} else {
+		return uint64(d)
+	}
+}
+
+func (n bigIntNumber) Uint64() uint64 {
+	return n.Int.Uint64()
+}
+
+func (n decimalNumber) Uint64() uint64 {
+	return decimal.Decimal(n).BigInt().Uint64()
+}
+
+func (n ulongNumber) Uint64() uint64 {
+	return uint64(n)
+}
+
+func (n floatNumber) Uint64() uint64 {
+	return doubleNumber(n).Uint64()
+}
+
 func (n longNumber) Double() float64 {
 	return float64(n)
 }
@@ -485,6 +737,14 @@ func (n decimalNumber) Double() float64 {
 	return v
 }
 
+func (n ulongNumber) Double() float64 {
+	return float64(n)
+}
+
+func (n floatNumber) Double() float64 {
+	return float64(n)
+}
+
 func (n longNumber) BigInt() *big.Int {
 	return big.NewInt(int64(n))
 }
@@ -508,6 +768,14 @@ func (n decimalNumber) BigInt() *big.Int {
 	return decimal.Decimal(n).Floor().Coefficient()
 }
 
+func (n ulongNumber) BigInt() *big.Int {
+	return new(big.Int).SetUint64(uint64(n))
+}
+
+func (n floatNumber) BigInt() *big.Int {
+	return doubleNumber(n).BigInt()
+}
+
 func (n longNumber) Decimal() decimal.Decimal {
 	return decimal.NewFromInt(int64(n))
 }
@@ -528,52 +796,521 @@ func (n decimalNumber) Decimal() decimal.Decimal {
 	return decimal.Decimal(n)
 }
 
+func (n ulongNumber) Decimal() decimal.Decimal {
+	return decimal.NewFromBigInt(n.BigInt(), 0)
+}
+
+func (n floatNumber) Decimal() decimal.Decimal {
+	f := float32(n)
+	if f != f {
+		return decimal.NewFromInt(0)
+	}
+	return decimal.NewFromFloat32(f)
+}
+
 func (n longNumber) Add(other Number) Number {
-	return Long(n.Long() + other.Long())
+	return addNumbers(n, other)
 }
 
 func (n doubleNumber) Add(other Number) Number {
-	left := float64(n)
-	right := other.Double()
-	if math.IsNaN(left) || math.IsNaN(right) {
-		return Nan()
-	}
-	return Double(left + right)
+	return addNumbers(n, other)
 }
 
 func (n bigIntNumber) Add(other Number) Number {
-	z := new(big.Int)
-	z.Add(n.Int, other.BigInt())
-	return BigInt(z)
+	return addNumbers(n, other)
 }
 
 func (n decimalNumber) Add(other Number) Number {
-	dec := decimal.Decimal(n)
-	return Decimal(dec.Add(other.Decimal()))
+	return addNumbers(n, other)
+}
+
+func (n ulongNumber) Add(other Number) Number {
+	return addNumbers(n, other)
+}
+
+func (n floatNumber) Add(other Number) Number {
+	return addNumbers(n, other)
 }
 
 func (n longNumber) Subtract(other Number) Number {
-	return Long(n.Long() - other.Long())
+	return subtractNumbers(n, other)
 }
 
 func (n doubleNumber) Subtract(other Number) Number {
-	left := float64(n)
-	right := other.Double()
-	if math.IsNaN(left) || math.IsNaN(right) {
-		return Nan()
-	}
-	return Double(left - right)
+	return subtractNumbers(n, other)
 }
 
 func (n bigIntNumber) Subtract(other Number) Number {
-	z := new(big.Int)
-	z.Sub(n.Int, other.BigInt())
-	return BigInt(z)
+	return subtractNumbers(n, other)
 }
 
 func (n decimalNumber) Subtract(other Number) Number {
-	dec := decimal.Decimal(n)
-	return Decimal(dec.Sub(other.Decimal()))
+	return subtractNumbers(n, other)
+}
+
+func (n ulongNumber) Subtract(other Number) Number {
+	return subtractNumbers(n, other)
+}
+
+func (n floatNumber) Subtract(other Number) Number {
+	return subtractNumbers(n, other)
+}
+
+func (n longNumber) Multiply(other Number) Number {
+	return multiplyNumbers(n, other)
+}
+
+func (n doubleNumber) Multiply(other Number) Number {
+	return multiplyNumbers(n, other)
+}
+
+func (n bigIntNumber) Multiply(other Number) Number {
+	return multiplyNumbers(n, other)
+}
+
+func (n decimalNumber) Multiply(other Number) Number {
+	return multiplyNumbers(n, other)
+}
+
+func (n ulongNumber) Multiply(other Number) Number {
+	return multiplyNumbers(n, other)
+}
+
+func (n floatNumber) Multiply(other Number) Number {
+	return multiplyNumbers(n, other)
+}
+
+func (n longNumber) Divide(other Number) Number {
+	return divideNumbers(n, other)
+}
+
+func (n doubleNumber) Divide(other Number) Number {
+	return divideNumbers(n, other)
+}
+
+func (n bigIntNumber) Divide(other Number) Number {
+	return divideNumbers(n, other)
+}
+
+func (n decimalNumber) Divide(other Number) Number {
+	return divideNumbers(n, other)
+}
+
+func (n ulongNumber) Divide(other Number) Number {
+	return divideNumbers(n, other)
+}
+
+func (n floatNumber) Divide(other Number) Number {
+	return divideNumbers(n, other)
+}
+
+func (n longNumber) Mod(other Number) Number {
+	return modNumbers(n, other)
+}
+
+func (n doubleNumber) Mod(other Number) Number {
+	return modNumbers(n, other)
+}
+
+func (n bigIntNumber) Mod(other Number) Number {
+	return modNumbers(n, other)
+}
+
+func (n decimalNumber) Mod(other Number) Number {
+	return modNumbers(n, other)
+}
+
+func (n ulongNumber) Mod(other Number) Number {
+	return modNumbers(n, other)
+}
+
+func (n floatNumber) Mod(other Number) Number {
+	return modNumbers(n, other)
+}
+
+func (n longNumber) Pow(other Number) Number {
+	return powNumbers(n, other)
+}
+
+func (n doubleNumber) Pow(other Number) Number {
+	return powNumbers(n, other)
+}
+
+func (n bigIntNumber) Pow(other Number) Number {
+	return powNumbers(n, other)
+}
+
+func (n decimalNumber) Pow(other Number) Number {
+	return powNumbers(n, other)
+}
+
+func (n ulongNumber) Pow(other Number) Number {
+	return powNumbers(n, other)
+}
+
+func (n floatNumber) Pow(other Number) Number {
+	return powNumbers(n, other)
+}
+
+func (n longNumber) Negate() Number {
+	if n == math.MinInt64 {
+		return BigInt(new(big.Int).Neg(n.BigInt()))
+	}
+	return Long(-int64(n))
+}
+
+func (n doubleNumber) Negate() Number {
+	return Double(-float64(n))
+}
+
+func (n bigIntNumber) Negate() Number {
+	return BigInt(new(big.Int).Neg(n.Int))
+}
+
+func (n decimalNumber) Negate() Number {
+	return Decimal(decimal.Decimal(n).Neg())
+}
+
+func (n ulongNumber) Negate() Number {
+	if n <= math.MaxInt64 {
+		return Long(-int64(n))
+	}
+	return BigInt(new(big.Int).Neg(n.BigInt()))
+}
+
+func (n floatNumber) Negate() Number {
+	return Float(-float32(n))
+}
+
+func (n longNumber) Abs() Number {
+	if n < 0 {
+		return n.Negate()
+	}
+	return n
+}
+
+func (n doubleNumber) Abs() Number {
+	return Double(math.Abs(float64(n)))
+}
+
+func (n bigIntNumber) Abs() Number {
+	return BigInt(new(big.Int).Abs(n.Int))
+}
+
+func (n decimalNumber) Abs() Number {
+	return Decimal(decimal.Decimal(n).Abs())
+}
+
+func (n ulongNumber) Abs() Number {
+	return n
+}
+
+func (n floatNumber) Abs() Number {
+	return Float(float32(math.Abs(float64(n))))
+}
+
+func (n longNumber) Sign() int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (n doubleNumber) Sign() int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (n bigIntNumber) Sign() int {
+	return n.Int.Sign()
+}
+
+func (n decimalNumber) Sign() int {
+	return decimal.Decimal(n).Sign()
+}
+
+func (n ulongNumber) Sign() int {
+	if n > 0 {
+		return 1
+	}
+	return 0
+}
+
+func (n floatNumber) Sign() int {
+	return doubleNumber(n).Sign()
+}
+
+/**
+	Type of the result of arithmetic operation
+
+	Same types give the same type, DECIMAL wins over others, then DOUBLE or FLOAT widened to DOUBLE, mix of integer types gives BIGINT.
+	LONG and ULONG results are promoted to BIGINT on overflow, DECIMAL with infinite DOUBLE gives DOUBLE.
+*/
+
+func promoteNumberType(a, b Number) NumberType {
+	at, bt := a.Type(), b.Type()
+	switch {
+	case at == bt:
+		return at
+	case at == DECIMAL || bt == DECIMAL:
+		if math.IsInf(a.Double(), 0) || math.IsInf(b.Double(), 0) {
+			return DOUBLE
+		}
+		return DECIMAL
+	case isFloatingType(at) || isFloatingType(bt):
+		return DOUBLE
+	default:
+		return BIGINT
+	}
+}
+
+func isFloatingType(t NumberType) bool {
+	return t == DOUBLE || t == FLOAT
+}
+
+type numberOp struct {
+	long    func(a, b int64) (int64, bool)
+	ulong   func(a, b uint64) (uint64, bool)
+	double  func(a, b float64) float64
+	bigInt  func(a, b *big.Int) *big.Int
+	decimal func(a, b decimal.Decimal) decimal.Decimal
+}
+
+func (op *numberOp) apply(a, b Number) Number {
+	if a.IsNaN() || b.IsNaN() {
+		return Nan()
+	}
+	switch promoteNumberType(a, b) {
+	case LONG:
+		if r, ok := op.long(a.Long(), b.Long()); ok {
+			return Long(r)
+		}
+		return BigInt(op.bigInt(a.BigInt(), b.BigInt()))
+	case ULONG:
+		if r, ok := op.ulong(a.Uint64(), b.Uint64()); ok {
+			return ULong(r)
+		}
+		return BigInt(op.bigInt(a.BigInt(), b.BigInt()))
+	case BIGINT:
+		return BigInt(op.bigInt(a.BigInt(), b.BigInt()))
+	case DECIMAL:
+		return Decimal(op.decimal(a.Decimal(), b.Decimal()))
+	case FLOAT:
+		return Float(float32(op.double(a.Double(), b.Double())))
+	default:
+		return Double(op.double(a.Double(), b.Double()))
+	}
+}
+
+var addOp = &numberOp{
+	long: func(a, b int64) (int64, bool) {
+		r := a + b
+		return r, (r > a) == (b > 0)
+	},
+	ulong: func(a, b uint64) (uint64, bool) {
+		r := a + b
+		return r, r >= a
+	},
+	double: func(a, b float64) float64 {
+		return a + b
+	},
+	bigInt: func(a, b *big.Int) *big.Int {
+		return new(big.Int).Add(a, b)
+	},
+	decimal: decimal.Decimal.Add,
+}
+
+var subtractOp = &numberOp{
+	long: func(a, b int64) (int64, bool) {
+		r := a - b
+		return r, (r < a) == (b > 0)
+	},
+	ulong: func(a, b uint64) (uint64, bool) {
+		return a - b, a >= b
+	},
+	double: func(a, b float64) float64 {
+		return a - b
+	},
+	bigInt: func(a, b *big.Int) *big.Int {
+		return new(big.Int).Sub(a, b)
+	},
+	decimal: decimal.Decimal.Sub,
+}
+
+var multiplyOp = &numberOp{
+	long: func(a, b int64) (int64, bool) {
+		if a == 0 || b == 0 {
+			return 0, true
+		}
+		r := a * b
+		if (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+			return r, false
+		}
+		return r, r/b == a
+	},
+	ulong: func(a, b uint64) (uint64, bool) {
+		if a == 0 || b == 0 {
+			return 0, true
+		}
+		r := a * b
+		return r, r/b == a
+	},
+	double: func(a, b float64) float64 {
+		return a * b
+	},
+	bigInt: func(a, b *big.Int) *big.Int {
+		return new(big.Int).Mul(a, b)
+	},
+	decimal: decimal.Decimal.Mul,
+}
+
+var divideOp = &numberOp{
+	long: func(a, b int64) (int64, bool) {
+		if a == math.MinInt64 && b == -1 {
+			return 0, false
+		}
+		return a / b, true
+	},
+	ulong: func(a, b uint64) (uint64, bool) {
+		return a / b, true
+	},
+	double: func(a, b float64) float64 {
+		return a / b
+	},
+	bigInt: func(a, b *big.Int) *big.Int {
+		return new(big.Int).Quo(a, b)
+	},
+	decimal: decimal.Decimal.Div,
+}
+
+var modOp = &numberOp{
+	long: func(a, b int64) (int64, bool) {
+		return a % b, true
+	},
+	ulong: func(a, b uint64) (uint64, bool) {
+		return a % b, true
+	},
+	double: math.Mod,
+	bigInt: func(a, b *big.Int) *big.Int {
+		return new(big.Int).Rem(a, b)
+	},
+	decimal: decimal.Decimal.Mod,
+}
+
+func addNumbers(a, b Number) Number {
+	return addOp.apply(a, b)
+}
+
+func subtractNumbers(a, b Number) Number {
+	return subtractOp.apply(a, b)
+}
+
+func multiplyNumbers(a, b Number) Number {
+	return multiplyOp.apply(a, b)
+}
+
+func divideNumbers(a, b Number) Number {
+	if b.Sign() == 0 {
+		return Nan()
+	}
+	return divideOp.apply(a, b)
+}
+
+func modNumbers(a, b Number) Number {
+	if b.Sign() == 0 {
+		return Nan()
+	}
+	return modOp.apply(a, b)
+}
+
+/**
+	Limit of bits in the exact integer power, larger results are calculated in DOUBLE
+*/
+
+const maxPowBits = 1 << 16
+
+/**
+	Integer and decimal base with non-negative integer exponent gives the exact result,
+	all other cases are calculated in DOUBLE
+*/
+
+func powNumbers(base, exp Number) Number {
+	if base.IsNaN() || exp.IsNaN() {
+		return Nan()
+	}
+	e, ok := integerExponent(exp)
+	if !ok || e < 0 {
+		return Double(math.Pow(base.Double(), exp.Double()))
+	}
+	switch base.Type() {
+	case LONG, ULONG, BIGINT:
+		b := base.BigInt()
+		if powOverflows(int64(b.BitLen()), e, maxPowBits) {
+			break
+		}
+		r := new(big.Int).Exp(b, big.NewInt(e), nil)
+		switch {
+		case exp.Type() == DECIMAL:
+			return Decimal(decimal.NewFromBigInt(r, 0))
+		case base.Type() == LONG && exp.Type() == LONG && r.IsInt64():
+			return Long(r.Int64())
+		case base.Type() == ULONG && (exp.Type() == LONG || exp.Type() == ULONG) && r.IsUint64():
+			return ULong(r.Uint64())
+		default:
+			return BigInt(r)
+		}
+	case DECIMAL:
+		d := base.Decimal()
+		scale := int64(d.Exponent())
+		if scale < 0 {
+			scale = -scale
+		}
+		if powOverflows(int64(d.Coefficient().BitLen()), e, maxPowBits) || powOverflows(scale, e, math.MaxInt32) {
+			break
+		}
+		r := new(big.Int).Exp(d.Coefficient(), big.NewInt(e), nil)
+		return Decimal(decimal.NewFromBigInt(r, int32(int64(d.Exponent())*e)))
+	}
+	return Double(math.Pow(base.Double(), exp.Double()))
+}
+
+/**
+	Checks x*e > limit for non-negative x and e without overflow of the product
+*/
+
+func powOverflows(x, e, limit int64) bool {
+	return x != 0 && e > limit/x
+}
+
+func integerExponent(exp Number) (int64, bool) {
+	switch exp.Type() {
+	case LONG:
+		return exp.Long(), true
+	case ULONG:
+		if exp.Uint64() <= math.MaxInt64 {
+			return exp.Long(), true
+		}
+	case BIGINT:
+		if exp.BigInt().IsInt64() {
+			return exp.Long(), true
+		}
+	case DECIMAL:
+		d := exp.Decimal()
+		if d.Equal(d.Truncate(0)) && d.IntPart() >= 0 {
+			return d.IntPart(), true
+		}
+	}
+	return 0, false
 }
 
 func (n longNumber) Equal(val Value) bool {
@@ -581,6 +1318,9 @@ func (n longNumber) Equal(val Value) bool {
 		return false
 	}
 	other := val.(Number)
+	if other.Type() == ULONG {
+		return other.Equal(n)
+	}
 	return n.Long() == other.Long()
 }
 
@@ -611,3 +1351,15 @@ func (n decimalNumber) Equal(val Value) bool {
 	return n.Decimal().Cmp(other.Decimal()) == 0
 }
 
+func (n ulongNumber) Equal(val Value) bool {
+	if val == nil || val.Kind() != NUMBER {
+		return false
+	}
+	other := val.(Number)
+	return !other.IsNaN() && compareNumbers(n, other) == 0
+}
+
+func (n floatNumber) Equal(val Value) bool {
+	return doubleNumber(n).Equal(val)
+}
+
@@ -19,11 +19,14 @@
 package value_test
 
 import (
+	"bytes"
 	"testing"
 	val "arpabet.pkg.is/value"
 	"github.com/stretchr/testify/require"
 	"math"
+	"math/big"
 	"encoding/json"
+	"github.com/shopspring/decimal"
 )
 
 
@@ -188,24 +191,24 @@ func TestParseNumber(t *testing.T) {
 
 	b = val.ParseNumber("123456789.123456789")
 
+	// not exact in double
 	require.Equal(t, val.NUMBER, b.Kind())
-	require.Equal(t, val.DOUBLE, b.Type())
-	require.Equal(t, "value.doubleNumber", b.Class().String())
-	require.Equal(t, "cb419d6f34547e6b75", val.Hex(b))
-	require.Equal(t, "123456789.12345679", val.Jsonify(b))
-	require.Equal(t, "123456789.12345679", b.String())
+	require.Equal(t, val.DECIMAL, b.Type())
+	require.Equal(t, "value.decimalNumber", b.Class().String())
+	require.Equal(t, "c70d02fffffff70201b69b4bacd05f15", val.Hex(b))
+	require.Equal(t, "123456789.123456789", b.Decimal().String())
 
 	c := val.ParseNumber("1.2345678912345679e+08")
 	DoubleEqual(t, b.Double(), c.Double())
 
 	b = val.ParseNumber("-123456789.123456789")
 
+	// not exact in double
 	require.Equal(t, val.NUMBER, b.Kind())
-	require.Equal(t, val.DOUBLE, b.Type())
-	require.Equal(t, "value.doubleNumber", b.Class().String())
-	require.Equal(t, "cbc19d6f34547e6b75", val.Hex(b))
-	require.Equal(t, "-123456789.12345679", val.Jsonify(b))
-	require.Equal(t, "-123456789.12345679", b.String())
+	require.Equal(t, val.DECIMAL, b.Type())
+	require.Equal(t, "value.decimalNumber", b.Class().String())
+	require.Equal(t, "c70d02fffffff70301b69b4bacd05f15", val.Hex(b))
+	require.Equal(t, "-123456789.123456789", b.Decimal().String())
 
 	c = val.ParseNumber("-1.2345678912345679e+08")
 	DoubleEqual(t, b.Double(), c.Double())
@@ -412,6 +415,170 @@ func TestSubtractNaNBoth(t *testing.T) {
 
 }
 
+func TestLongOverflow(t *testing.T) {
+
+	max := val.Long(math.MaxInt64)
+	min := val.Long(math.MinInt64)
+
+	c := max.Add(val.Long(1))
+	require.Equal(t, val.BIGINT, c.Type())
+	require.Equal(t, "9223372036854775808", c.BigInt().String())
+
+	c = min.Subtract(val.Long(1))
+	require.Equal(t, val.BIGINT, c.Type())
+	require.Equal(t, "-9223372036854775809", c.BigInt().String())
+
+	c = max.Multiply(val.Long(2))
+	require.Equal(t, val.BIGINT, c.Type())
+	require.Equal(t, "18446744073709551614", c.BigInt().String())
+
+	c = min.Multiply(val.Long(-1))
+	require.Equal(t, val.BIGINT, c.Type())
+	c = min.Divide(val.Long(-1))
+	require.Equal(t, val.BIGINT, c.Type())
+	c = min.Negate()
+	require.Equal(t, val.BIGINT, c.Type())
+	c = min.Abs()
+	require.Equal(t, val.BIGINT, c.Type())
+	require.Equal(t, "9223372036854775808", c.BigInt().String())
+
+	c = max.Subtract(val.Long(1))
+	require.Equal(t, val.LONG, c.Type())
+	require.Equal(t, int64(math.MaxInt64-1), c.Long())
+
+	c = val.Long(-3).Multiply(val.Long(4))
+	require.Equal(t, val.LONG, c.Type())
+	require.Equal(t, int64(-12), c.Long())
+
+}
+
+func TestNumberPromotion(t *testing.T) {
+
+	dec := val.Decimal(decimal.RequireFromString("0.1"))
+
+	c := val.Long(1).Add(val.Double(0.5))
+	require.Equal(t, val.DOUBLE, c.Type())
+	DoubleEqual(t, 1.5, c.Double())
+
+	c = val.Long(1).Add(val.BigInt(big.NewInt(2)))
+	require.Equal(t, val.BIGINT, c.Type())
+	require.Equal(t, int64(3), c.Long())
+
+	c = dec.Add(val.Double(0.2))
+	require.Equal(t, val.DECIMAL, c.Type())
+	require.Equal(t, "0.3", c.Decimal().String())
+
+	c = val.Double(0.2).Multiply(dec)
+	require.Equal(t, val.DECIMAL, c.Type())
+	require.Equal(t, "0.02", c.Decimal().String())
+
+	c = dec.Add(val.Double(math.Inf(1)))
+	require.Equal(t, val.DOUBLE, c.Type())
+	require.True(t, math.IsInf(c.Double(), 1))
+
+	c = dec.Add(val.Nan())
+	require.True(t, c.IsNaN())
+
+}
+
+func TestDivideMod(t *testing.T) {
+
+	c := val.Long(7).Divide(val.Long(2))
+	require.Equal(t, val.LONG, c.Type())
+	require.Equal(t, int64(3), c.Long())
+
+	c = val.Long(-7).Mod(val.Long(2))
+	require.Equal(t, int64(-1), c.Long())
+
+	c = val.Double(7).Divide(val.Long(2))
+	require.Equal(t, val.DOUBLE, c.Type())
+	DoubleEqual(t, 3.5, c.Double())
+
+	c = val.Decimal(decimal.RequireFromString("10")).Divide(val.Long(4))
+	require.Equal(t, val.DECIMAL, c.Type())
+	require.Equal(t, "2.5", c.Decimal().String())
+
+	c = val.Decimal(decimal.RequireFromString("10.5")).Mod(val.Long(4))
+	require.Equal(t, "2.5", c.Decimal().String())
+
+	c = val.Double(7.5).Mod(val.Double(2))
+	DoubleEqual(t, 1.5, c.Double())
+
+	for _, zero := range []val.Number{val.Long(0), val.Double(0), val.BigInt(big.NewInt(0)), val.Decimal(decimal.Zero)} {
+		require.True(t, val.Long(1).Divide(zero).IsNaN())
+		require.True(t, val.Double(1).Divide(zero).IsNaN())
+		require.True(t, val.Decimal(decimal.New(1, 0)).Mod(zero).IsNaN())
+	}
+
+}
+
+func TestPowNumber(t *testing.T) {
+
+	c := val.Long(2).Pow(val.Long(10))
+	require.Equal(t, val.LONG, c.Type())
+	require.Equal(t, int64(1024), c.Long())
+
+	c = val.Long(2).Pow(val.Long(100))
+	require.Equal(t, val.BIGINT, c.Type())
+	require.Equal(t, "1267650600228229401496703205376", c.BigInt().String())
+
+	c = val.Decimal(decimal.RequireFromString("1.1")).Pow(val.Long(2))
+	require.Equal(t, val.DECIMAL, c.Type())
+	require.Equal(t, "1.21", c.Decimal().String())
+
+	c = val.Long(2).Pow(val.Long(-1))
+	require.Equal(t, val.DOUBLE, c.Type())
+	DoubleEqual(t, 0.5, c.Double())
+
+	c = val.Long(4).Pow(val.Double(0.5))
+	require.Equal(t, val.DOUBLE, c.Type())
+	DoubleEqual(t, 2, c.Double())
+
+	require.True(t, val.Nan().Pow(val.Long(2)).IsNaN())
+
+}
+
+func TestPowHugeExponent(t *testing.T) {
+
+	huge := val.Long(1 << 62)
+
+	c := val.Long(2).Pow(huge)
+	require.Equal(t, val.DOUBLE, c.Type())
+	require.True(t, math.IsInf(c.Double(), 1))
+
+	c = val.Long(0).Pow(huge)
+	require.Equal(t, val.LONG, c.Type())
+	require.Equal(t, int64(0), c.Long())
+
+	c = val.Decimal(decimal.RequireFromString("1.1")).Pow(huge)
+	require.Equal(t, val.DOUBLE, c.Type())
+	require.True(t, math.IsInf(c.Double(), 1))
+
+	c = val.Decimal(decimal.RequireFromString("0.1")).Pow(val.Decimal(decimal.New(1, 18)))
+	require.Equal(t, val.DOUBLE, c.Type())
+	DoubleEqual(t, 0, c.Double())
+
+	c = val.Decimal(decimal.New(1, 100000)).Pow(val.Long(30000))
+	require.Equal(t, val.DOUBLE, c.Type())
+	require.True(t, math.IsInf(c.Double(), 1))
+
+}
+
+func TestSignNumber(t *testing.T) {
+
+	require.Equal(t, -1, val.Long(-5).Sign())
+	require.Equal(t, 0, val.Long(0).Sign())
+	require.Equal(t, 1, val.Double(0.1).Sign())
+	require.Equal(t, 0, val.Nan().Sign())
+	require.Equal(t, -1, val.BigInt(big.NewInt(-1)).Sign())
+	require.Equal(t, 1, val.Decimal(decimal.RequireFromString("0.001")).Sign())
+
+	require.Equal(t, int64(5), val.Long(-5).Abs().Long())
+	require.Equal(t, "-0.5", val.Decimal(decimal.RequireFromString("0.5")).Negate().Decimal().String())
+	DoubleEqual(t, -2.5, val.Double(2.5).Negate().Double())
+
+}
+
 func DoubleEqual(t *testing.T, left, right float64) {
 	require.True(t, math.Abs(left - right) < 0.00001)
 }
@@ -465,4 +632,210 @@ func TestPackDouble(t *testing.T) {
 
 	}
 
-}
\ No newline at end of file
+}
+
+func TestULong(t *testing.T) {
+
+	b := val.ULong(math.MaxUint64)
+	require.Equal(t, val.ULONG, b.Type())
+	require.Equal(t, uint64(math.MaxUint64), b.Uint64())
+	require.Equal(t, "18446744073709551615", b.String())
+
+	bin, _ := b.MarshalBinary()
+	require.Equal(t, []byte{0xcf, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, bin)
+
+	mp, _ := val.Pack(b)
+	require.Equal(t, bin, mp)
+
+	c, err := val.Unpack(mp, false)
+	require.NoError(t, err)
+	require.Equal(t, val.ULONG, c.(val.Number).Type())
+	require.True(t, b.Equal(c))
+
+	c, err = val.Unpack([]byte{0xcf, 0, 0, 0, 0, 0, 0, 0, 0x7b}, false)
+	require.NoError(t, err)
+	require.Equal(t, val.LONG, c.(val.Number).Type())
+	require.Equal(t, int64(123), c.(val.Number).Long())
+
+	n := val.ParseNumber("18446744073709551615")
+	require.Equal(t, val.ULONG, n.Type())
+	require.True(t, b.Equal(n))
+
+	n = val.ParseNumber("9223372036854775807")
+	require.Equal(t, val.LONG, n.Type())
+
+	require.True(t, val.ULong(5).Equal(val.Long(5)))
+	require.True(t, val.Long(5).Equal(val.ULong(5)))
+	require.False(t, val.Long(-1).Equal(val.ULong(math.MaxUint64)))
+
+	s := b.Add(val.ULong(1))
+	require.Equal(t, val.BIGINT, s.Type())
+	require.Equal(t, "18446744073709551616", s.BigInt().String())
+
+	s = val.ULong(1).Subtract(val.ULong(2))
+	require.Equal(t, val.BIGINT, s.Type())
+	require.Equal(t, int64(-1), s.Long())
+
+	s = val.ULong(1 << 62).Multiply(val.ULong(2))
+	require.Equal(t, val.ULONG, s.Type())
+	require.Equal(t, uint64(1<<63), s.Uint64())
+
+	require.Equal(t, -1, val.Compare(val.Long(math.MaxInt64), b))
+	require.Equal(t, 1, val.Compare(b, val.Double(1e19)))
+
+	key := val.EncodeKey(b)
+	require.Equal(t, -1, bytes.Compare(val.EncodeKey(val.Long(math.MaxInt64)), key))
+
+	list, err := val.DecodeKey(key)
+	require.NoError(t, err)
+	require.Equal(t, val.ULONG, list.GetAt(0).(val.Number).Type())
+	require.True(t, b.Equal(list.GetAt(0)))
+
+	testPackUnpack(t, b)
+
+}
+
+func TestFloat(t *testing.T) {
+
+	b := val.Float(1.1)
+	require.Equal(t, val.FLOAT, b.Type())
+	require.Equal(t, "1.1", b.String())
+	require.Equal(t, float32(1.1), b.Object())
+
+	j, _ := b.MarshalJSON()
+	require.Equal(t, "1.1", string(j))
+
+	bin, _ := b.MarshalBinary()
+	require.Equal(t, []byte{0xca, 0x3f, 0x8c, 0xcc, 0xcd}, bin)
+
+	mp, _ := val.Pack(b)
+	require.Equal(t, bin, mp)
+
+	c, err := val.Unpack(mp, false)
+	require.NoError(t, err)
+	require.Equal(t, val.FLOAT, c.(val.Number).Type())
+	require.Equal(t, float64(float32(1.1)), c.(val.Number).Double())
+
+	mp2, _ := val.Pack(c)
+	require.Equal(t, mp, mp2)
+
+	list := val.SolidList([]val.Value{val.Float(1.5), val.Float(-2.25)})
+	mp, _ = val.Pack(list)
+	require.Equal(t, []byte{0x92, 0xca, 0x3f, 0xc0, 0, 0, 0xca, 0xc0, 0x10, 0, 0}, mp)
+
+	require.True(t, val.Float(float32(math.NaN())).IsNaN())
+	require.True(t, val.Float(1.5).Equal(val.Double(1.5)))
+
+	s := val.Float(1.5).Add(val.Float(2))
+	require.Equal(t, val.FLOAT, s.Type())
+	require.Equal(t, 3.5, s.Double())
+
+	s = val.Float(1.5).Add(val.Double(2))
+	require.Equal(t, val.DOUBLE, s.Type())
+
+	require.Equal(t, 0, val.Compare(val.Float(0.5), val.Double(0.5)))
+	require.Equal(t, -1, val.Compare(val.Float(float32(math.Inf(-1))), val.Long(math.MinInt64)))
+
+	key, err := val.DecodeKey(val.EncodeKey(b))
+	require.NoError(t, err)
+	require.Equal(t, val.FLOAT, key.GetAt(0).(val.Number).Type())
+	require.Equal(t, b.Double(), key.GetAt(0).(val.Number).Double())
+
+	testPackUnpack(t, b)
+
+}
+
+func TestParseNumberLiterals(t *testing.T) {
+
+	b := val.ParseNumber("1e3")
+	require.Equal(t, val.DOUBLE, b.Type())
+	require.Equal(t, float64(1000), b.Double())
+
+	b = val.ParseNumber("-1.5E-7")
+	require.Equal(t, val.DOUBLE, b.Type())
+	require.Equal(t, -1.5e-7, b.Double())
+
+	b = val.ParseNumber("123456789012345678901234567890")
+	require.Equal(t, val.BIGINT, b.Type())
+	require.Equal(t, "123456789012345678901234567890", b.BigInt().String())
+
+	b = val.ParseNumber("-123456789012345678901234567890")
+	require.Equal(t, val.BIGINT, b.Type())
+	require.Equal(t, "-123456789012345678901234567890", b.BigInt().String())
+
+	b = val.ParseNumber("1.5e400")
+	require.Equal(t, val.DECIMAL, b.Type())
+	require.Equal(t, int32(399), b.Decimal().Exponent())
+
+	b = val.ParseNumber("-2.5e-400")
+	require.Equal(t, val.DECIMAL, b.Type())
+	require.Equal(t, "-25", b.Decimal().Coefficient().String())
+	require.Equal(t, int32(-401), b.Decimal().Exponent())
+
+	b = val.ParseNumber("0.0")
+	require.Equal(t, val.DOUBLE, b.Type())
+
+	require.True(t, val.ParseNumber("1e").IsNaN())
+
+}
+
+func TestJSONNumberStyle(t *testing.T) {
+
+	b := val.BigInt(big.NewInt(-1234))
+	d := val.Decimal(decimal.New(150, -2))
+
+	require.Equal(t, "\"-0x04d2\"", val.Jsonify(b))
+	require.Equal(t, "\"0x96x-02\"", val.Jsonify(d))
+	require.Equal(t, "\"0x96x-02\"", val.JsonifyWith(d, val.HexNumbers))
+
+	j, _ := json.Marshal(&testNumberStruct{d})
+	require.Equal(t, "{\"N\":\"0x96x-02\"}", string(j))
+
+	require.Equal(t, "-1234", val.JsonifyWith(b, val.PlainNumbers))
+	require.Equal(t, "1.50", val.JsonifyWith(d, val.PlainNumbers))
+	require.Equal(t, "1500", val.JsonifyWith(val.Decimal(decimal.New(15, 2)), val.PlainNumbers))
+
+	// elements of collections
+	list := val.SolidList([]val.Value{b, val.EmptyMap().Put("d", d), val.SparseList([]val.ListItem{val.Item(2, d)}, true), val.Long(1)})
+	require.Equal(t, "[-1234,{\"d\": 1.50},{\"2\": 1.50},1]", val.JsonifyWith(list, val.PlainNumbers))
+	require.Equal(t, val.Jsonify(list), val.JsonifyWith(list, val.HexNumbers))
+
+	bi, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+	require.Equal(t, "-123456789012345678901234567890", val.JsonifyWith(val.BigInt(bi), val.PlainNumbers))
+
+	c, err := val.ParseJSON([]byte("[-123456789012345678901234567890, 1.50, 1e400]"))
+	require.NoError(t, err)
+	require.Equal(t, val.BIGINT, c.(val.List).GetAt(0).(val.Number).Type())
+	require.True(t, val.BigInt(bi).Equal(c.(val.List).GetAt(0)))
+	require.True(t, d.Equal(c.(val.List).GetAt(1)))
+	require.Equal(t, val.DECIMAL, c.(val.List).GetAt(2).(val.Number).Type())
+
+	require.Equal(t, "\"-1234\"", val.JsonifyWith(b, val.QuotedNumbers))
+	require.Equal(t, "\"1.50\"", val.JsonifyWith(d, val.QuotedNumbers))
+
+}
+
+func TestLongDecimalRoundTrip(t *testing.T) {
+
+	pi := val.ParseNumber("3.14159265358979323846264338327950288")
+	require.Equal(t, val.DECIMAL, pi.Type())
+	require.Equal(t, "3.14159265358979323846264338327950288", pi.Decimal().String())
+
+	require.Equal(t, val.DOUBLE, val.ParseNumber("0.1").Type())
+	require.Equal(t, val.DOUBLE, val.ParseNumber("1.50").Type())
+	require.Equal(t, val.DOUBLE, val.ParseNumber("1e300").Type())
+
+	for _, str := range []string{"0.1234567890123456789", "-3.14159265358979323846264338327950288", "1e400", "1e-400"} {
+		dec, _ := decimal.NewFromString(str)
+		d := val.Decimal(dec)
+		for _, style := range []val.NumberStyle{val.HexNumbers, val.PlainNumbers, val.QuotedNumbers} {
+			c, err := val.ParseJSON([]byte(val.JsonifyWith(d, style)))
+			require.NoError(t, err)
+			if style == val.QuotedNumbers {
+				c = val.ParseNumber(c.String())
+			}
+			require.True(t, d.Equal(c), "%s %v %v", str, style, c)
+		}
+	}
+
+}
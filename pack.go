@@ -35,6 +35,12 @@ const (
 	MaxExt
 )
 
+/**
+	Timestamp extension type -1 defined by MessagePack spec
+ */
+
+const TimeExt Ext = 0xff
+
 
 type Packer interface {
 
@@ -44,8 +50,12 @@ type Packer interface {
 
 	PackLong(int64)
 
+	PackULong(uint64)
+
 	PackDouble(float64)
 
+	PackFloat(float32)
+
 	PackStr(string)
 
 	PackBin([]byte)
@@ -76,8 +86,12 @@ type Writer interface {
 
 	WriteLong(val int64) []byte
 
+	WriteULong(val uint64) []byte
+
 	WriteDouble(val float64) []byte
 
+	WriteFloat(val float32) []byte
+
 	WriteBinHeader(len int) []byte
 
 	WriteStrHeader(len int) []byte
@@ -112,6 +126,8 @@ const (
 	ListHeader
 	MapHeader
 	ExtHeader
+	ULongToken
+	FloatToken
 )
 
 type Unpacker interface {
@@ -139,8 +155,12 @@ type Parser interface {
 
 	ParseLong([]byte) int64
 
+	ParseULong([]byte) uint64
+
 	ParseDouble([]byte) float64
 
+	ParseFloat([]byte) float32
+
 	ParseBin([]byte) int
 
 	ParseStr([]byte) int
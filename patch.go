@@ -0,0 +1,298 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value
+
+import (
+	"fmt"
+	"strings"
+)
+
+/**
+	JSON Patch (RFC 6902) and JSON Merge Patch (RFC 7396) over immutable Values
+
+	Patches are applied by copy-on-write methods Put, Remove, InsertAt, RemoveAt,
+	so the original document stays untouched.
+*/
+
+/**
+	Patch operation is not valid: unknown op, missing member or malformed pointer
+*/
+
+type PatchOpError struct {
+	Index  int
+	Op     string
+	Reason string
+}
+
+func (e *PatchOpError) Error() string {
+	return fmt.Sprintf("patch: invalid operation %d '%s', %s", e.Index, e.Op, e.Reason)
+}
+
+/**
+	Path of the patch operation does not exist in the document
+*/
+
+type PatchPathError struct {
+	Index int
+	Op    string
+	Path  string
+	Err   error
+}
+
+func (e *PatchPathError) Error() string {
+	return fmt.Sprintf("patch: operation %d '%s' fail on path '%s', %v", e.Index, e.Op, e.Path, e.Err)
+}
+
+func (e *PatchPathError) Unwrap() error {
+	return e.Err
+}
+
+/**
+	Value in the document is not equal to the value in the "test" operation
+*/
+
+type PatchTestError struct {
+	Index    int
+	Path     string
+	Expected Value
+	Actual   Value
+}
+
+func (e *PatchTestError) Error() string {
+	return fmt.Sprintf("patch: test operation %d fail on path '%s', expected %s, actual %s", e.Index, e.Path, Jsonify(e.Expected), Jsonify(e.Actual))
+}
+
+/**
+	Applies JSON Patch, every element of the patch is a Map with "op", "path", "value" and "from" members
+
+	Nil patch is the same as empty one and returns the document as is.
+*/
+
+func ApplyPatch(doc Value, patch List) (Value, error) {
+	if patch == nil {
+		return doc, nil
+	}
+	for i, item := range patch.Values() {
+		var err error
+		doc, err = applyPatchOp(doc, i, item)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+func applyPatchOp(doc Value, index int, item Value) (Value, error) {
+
+	if item == nil || item.Kind() != MAP {
+		return nil, &PatchOpError{Index: index, Reason: "operation must be an object"}
+	}
+	op := item.(Map)
+
+	name := ""
+	if s := op.GetString("op"); s != nil {
+		name = s.String()
+	}
+
+	pointer := op.GetString("path")
+	if pointer == nil {
+		return nil, &PatchOpError{Index: index, Op: name, Reason: "missing 'path'"}
+	}
+	path, err := parsePointer(pointer.String())
+	if err != nil {
+		return nil, &PatchOpError{Index: index, Op: name, Reason: err.Error()}
+	}
+
+	pathError := func(p string, err error) error {
+		return &PatchPathError{Index: index, Op: name, Path: p, Err: err}
+	}
+
+	switch name {
+	case "add", "replace", "test":
+		value, ok := op.Get("value")
+		if !ok {
+			return nil, &PatchOpError{Index: index, Op: name, Reason: "missing 'value'"}
+		}
+		switch name {
+		case "add":
+			doc, err = patchAdd(doc, path, value)
+		case "replace":
+			doc, err = patchReplace(doc, path, value)
+		default:
+			actual, ok := getPath(doc, path)
+			if !ok {
+				return nil, pathError(pointer.String(), ErrPathNotFound)
+			}
+			if !Equal(value, actual) {
+				return nil, &PatchTestError{Index: index, Path: pointer.String(), Expected: value, Actual: actual}
+			}
+		}
+		if err != nil {
+			return nil, pathError(pointer.String(), err)
+		}
+		return doc, nil
+
+	case "remove":
+		if doc, err = patchRemove(doc, path); err != nil {
+			return nil, pathError(pointer.String(), err)
+		}
+		return doc, nil
+
+	case "move", "copy":
+		fromPointer := op.GetString("from")
+		if fromPointer == nil {
+			return nil, &PatchOpError{Index: index, Op: name, Reason: "missing 'from'"}
+		}
+		from, err := parsePointer(fromPointer.String())
+		if err != nil {
+			return nil, &PatchOpError{Index: index, Op: name, Reason: err.Error()}
+		}
+		value, ok := getPath(doc, from)
+		if !ok {
+			return nil, pathError(fromPointer.String(), ErrPathNotFound)
+		}
+		if name == "move" {
+			if fromPointer.String() == pointer.String() {
+				return doc, nil
+			}
+			if strings.HasPrefix(pointer.String(), fromPointer.String()+"/") {
+				return nil, &PatchOpError{Index: index, Op: name, Reason: "can not move value into one of its children"}
+			}
+			if doc, err = patchRemove(doc, from); err != nil {
+				return nil, pathError(fromPointer.String(), err)
+			}
+		}
+		if doc, err = patchAdd(doc, path, value); err != nil {
+			return nil, pathError(pointer.String(), err)
+		}
+		return doc, nil
+
+	default:
+		return nil, &PatchOpError{Index: index, Op: name, Reason: "unknown operation"}
+	}
+}
+
+func patchAdd(doc Value, path []string, value Value) (Value, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return updatePath(doc, path, func(parent Value, token string) (Value, error) {
+		return addChild(parent, token, value)
+	})
+}
+
+func patchReplace(doc Value, path []string, value Value) (Value, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return updatePath(doc, path, func(parent Value, token string) (Value, error) {
+		return replaceChild(parent, token, value)
+	})
+}
+
+func patchRemove(doc Value, path []string) (Value, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+	return updatePath(doc, path, removeChild)
+}
+
+/**
+	Creates JSON Patch that transforms one value to another
+*/
+
+func CreatePatch(from, to Value, options ...DiffOption) List {
+	var ops []Value
+	for _, op := range Diff(from, to, options...) {
+		var m Map
+		switch op.Type {
+		case ADDED:
+			m = EmptyMap().Put("op", Utf8("add")).Put("value", op.New)
+		case REMOVED:
+			if len(op.Path) == 0 {
+				m = EmptyMap().Put("op", Utf8("replace")).Put("value", nil)
+			} else {
+				m = EmptyMap().Put("op", Utf8("remove"))
+			}
+		case CHANGED:
+			m = EmptyMap().Put("op", Utf8("replace")).Put("value", op.New)
+		}
+		ops = append(ops, m.Put("path", Utf8(op.Pointer())))
+	}
+	if len(ops) == 0 {
+		return EmptyList()
+	}
+	return SolidList(ops)
+}
+
+/**
+	Applies JSON Merge Patch, null values in the patch remove keys
+*/
+
+func ApplyMergePatch(doc, patch Value) Value {
+	if patch == nil || patch.Kind() != MAP {
+		return patch
+	}
+	var target Map
+	if doc != nil && doc.Kind() == MAP {
+		target = doc.(Map)
+	} else {
+		target = EmptyMap()
+	}
+	for _, entry := range patch.(Map).Entries() {
+		if IsNull(entry.Value()) {
+			target = target.Remove(entry.Key())
+		} else {
+			old, _ := target.Get(entry.Key())
+			target = target.Put(entry.Key(), ApplyMergePatch(old, entry.Value()))
+		}
+	}
+	return target
+}
+
+/**
+	Creates JSON Merge Patch that transforms one value to another
+
+	Merge Patch can not set null values, such keys are removed instead
+*/
+
+func CreateMergePatch(from, to Value) Value {
+	if from == nil || to == nil || from.Kind() != MAP || to.Kind() != MAP {
+		return to
+	}
+	src := from.(Map)
+	dst := to.(Map)
+	patch := EmptyMap()
+	for _, key := range uniqueKeys(src.Keys()) {
+		if _, ok := dst.Get(key); !ok {
+			patch = patch.Put(key, nil)
+		}
+	}
+	for _, entry := range dst.Entries() {
+		old, ok := src.Get(entry.Key())
+		if !ok || !Equal(old, entry.Value()) {
+			if ok && old != nil && old.Kind() == MAP && entry.Value() != nil && entry.Value().Kind() == MAP {
+				patch = patch.Put(entry.Key(), CreateMergePatch(old, entry.Value()))
+			} else {
+				patch = patch.Put(entry.Key(), entry.Value())
+			}
+		}
+	}
+	return patch
+}
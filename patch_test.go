@@ -0,0 +1,141 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value_test
+
+import (
+	val "arpabet.pkg.is/value"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func testParseJSON(t *testing.T, str string) val.Value {
+	v, err := val.ParseJSON([]byte(str))
+	require.Nil(t, err)
+	return v
+}
+
+func testEqualJSON(t *testing.T, expected string, actual val.Value) {
+	require.True(t, val.Equal(testParseJSON(t, expected), actual), "expected %s, actual %s", expected, val.Jsonify(actual))
+}
+
+func testApplyPatch(t *testing.T, doc, patch, expected string) {
+	d := testParseJSON(t, doc)
+	actual, err := val.ApplyPatch(d, testParseJSON(t, patch).(val.List))
+	require.Nil(t, err)
+	testEqualJSON(t, expected, actual)
+	testEqualJSON(t, doc, d)
+}
+
+func TestApplyPatch(t *testing.T) {
+
+	testApplyPatch(t, `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`)
+	testApplyPatch(t, `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`)
+	testApplyPatch(t, `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":"qux"}]`, `{"foo":["bar","qux"]}`)
+	testApplyPatch(t, `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`)
+	testApplyPatch(t, `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`)
+	testApplyPatch(t, `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`)
+	testApplyPatch(t, `{"foo":{"bar":"baz","waldo":"fred"},"qux":{"corge":"grault"}}`, `[{"op":"move","from":"/foo/waldo","path":"/qux/thud"}]`, `{"foo":{"bar":"baz"},"qux":{"corge":"grault","thud":"fred"}}`)
+	testApplyPatch(t, `{"foo":["all","grass","cows","eat"]}`, `[{"op":"move","from":"/foo/1","path":"/foo/3"}]`, `{"foo":["all","cows","eat","grass"]}`)
+	testApplyPatch(t, `{"foo":["bar"]}`, `[{"op":"copy","from":"/foo/0","path":"/baz"}]`, `{"baz":"bar","foo":["bar"]}`)
+	testApplyPatch(t, `{"baz":"qux","foo":["a",2,"c"]}`, `[{"op":"test","path":"/baz","value":"qux"},{"op":"test","path":"/foo/1","value":2}]`, `{"baz":"qux","foo":["a",2,"c"]}`)
+	testApplyPatch(t, `{"a/b":1,"m~n":2}`, `[{"op":"remove","path":"/a~1b"},{"op":"replace","path":"/m~0n","value":null}]`, `{"m~n":null}`)
+	testApplyPatch(t, `{"foo":"bar"}`, `[{"op":"replace","path":"","value":[1]}]`, `[1]`)
+
+}
+
+func TestApplyPatchErrors(t *testing.T) {
+
+	doc := testParseJSON(t, `{"baz":"qux","foo":["a",2,"c"]}`)
+
+	_, err := val.ApplyPatch(doc, testParseJSON(t, `[{"op":"test","path":"/baz","value":"bar"}]`).(val.List))
+	var testErr *val.PatchTestError
+	require.True(t, errors.As(err, &testErr))
+	require.Equal(t, "/baz", testErr.Path)
+	testEqualJSON(t, `"qux"`, testErr.Actual)
+
+	_, err = val.ApplyPatch(doc, testParseJSON(t, `[{"op":"test","path":"/baz","value":"qux"},{"op":"remove","path":"/foo/5"}]`).(val.List))
+	var pathErr *val.PatchPathError
+	require.True(t, errors.As(err, &pathErr))
+	require.Equal(t, 1, pathErr.Index)
+	require.Equal(t, "/foo/5", pathErr.Path)
+	require.True(t, errors.Is(err, val.ErrPathNotFound))
+
+	_, err = val.ApplyPatch(doc, testParseJSON(t, `[{"op":"add","path":"/missing/x","value":1}]`).(val.List))
+	require.True(t, errors.As(err, &pathErr))
+
+	var opErr *val.PatchOpError
+	for _, patch := range []string{
+		`[{"op":"add","path":"/x"}]`,
+		`[{"op":"unknown","path":"/x"}]`,
+		`[{"op":"remove","path":"x"}]`,
+		`[{"op":"move","from":"/foo","path":"/foo/0"}]`,
+		`[1]`,
+	} {
+		_, err = val.ApplyPatch(doc, testParseJSON(t, patch).(val.List))
+		require.True(t, errors.As(err, &opErr), patch)
+	}
+
+}
+
+func TestCreatePatch(t *testing.T) {
+
+	from := testCreateMap()
+	to := from.Put("list", from.GetList("list").RemoveAt(0).Append(val.Utf8("x"))).Remove("5").Put("new", val.EmptyMap())
+
+	patch := val.CreatePatch(from, to)
+	actual, err := val.ApplyPatch(from, patch)
+	require.Nil(t, err)
+	require.True(t, val.Equal(to, actual))
+
+	require.Equal(t, 0, val.CreatePatch(from, testCreateMap()).Len())
+	testEqualJSON(t, `[{"op":"replace","path":"","value":1}]`, val.CreatePatch(from, val.Long(1)))
+
+}
+
+func TestMergePatch(t *testing.T) {
+
+	doc := testParseJSON(t, `{"title":"Goodbye!","author":{"givenName":"John","familyName":"Doe"},"tags":["example","sample"],"content":"This will be unchanged"}`)
+	patch := testParseJSON(t, `{"title":"Hello!","phoneNumber":"+01-555-1234","author":{"familyName":null},"tags":["example"]}`)
+
+	actual := val.ApplyMergePatch(doc, patch)
+	testEqualJSON(t, `{"author":{"givenName":"John"},"content":"This will be unchanged","phoneNumber":"+01-555-1234","tags":["example"],"title":"Hello!"}`, actual)
+	require.Equal(t, "Goodbye!", doc.(val.Map).GetString("title").String())
+
+	testEqualJSON(t, `{"a":{"bb":{}}}`, val.ApplyMergePatch(testParseJSON(t, `{}`), testParseJSON(t, `{"a":{"bb":{"ccc":null}}}`)))
+	testEqualJSON(t, `["c"]`, val.ApplyMergePatch(testParseJSON(t, `{"a":"b"}`), testParseJSON(t, `["c"]`)))
+
+	created := val.CreateMergePatch(doc, actual)
+	require.True(t, val.Equal(actual, val.ApplyMergePatch(doc, created)))
+
+}
+
+func TestApplyNilPatch(t *testing.T) {
+
+	doc := testParseJSON(t, `{"foo":"bar"}`)
+
+	res, err := val.ApplyPatch(doc, nil)
+	require.NoError(t, err)
+	require.True(t, doc.Equal(res))
+
+	res, err = val.ApplyPatch(doc, val.EmptyList())
+	require.NoError(t, err)
+	require.True(t, doc.Equal(res))
+
+}
@@ -0,0 +1,388 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value
+
+import (
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+)
+
+/**
+	Navigation in the Value tree by the path of tokens
+
+	Token is a Map key or decimal index of the List element,
+	for solidListValue index is a position, for sparseListValue index is a key.
+*/
+
+var ErrPathNotFound = errors.New("path not found")
+
+func isSparseList(val Value) bool {
+	_, ok := val.(sparseListValue)
+	return ok
+}
+
+func parseIndex(token string) (int, bool) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, false
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 {
+		return 0, false
+	}
+	return i, true
+}
+
+/**
+	Gets child by the token
+
+	return (value or nil, true) or (nil, false)
+*/
+
+func childOf(parent Value, token string) (Value, bool) {
+	if parent == nil {
+		return nil, false
+	}
+	switch parent.Kind() {
+	case MAP:
+		return parent.(Map).Get(token)
+	case LIST:
+		i, ok := parseIndex(token)
+		if !ok {
+			return nil, false
+		}
+		list := parent.(List)
+		if isSparseList(parent) {
+			values := list.Select(i)
+			if len(values) == 0 {
+				return nil, false
+			}
+			return values[0], true
+		}
+		if i >= list.Len() {
+			return nil, false
+		}
+		return list.GetAt(i), true
+	default:
+		return nil, false
+	}
+}
+
+/**
+	Gets value by the path of tokens
+
+	return (value or nil, true) or (nil, false)
+*/
+
+func getPath(root Value, path []string) (Value, bool) {
+	val := root
+	for _, token := range path {
+		var ok bool
+		if val, ok = childOf(val, token); !ok {
+			return nil, false
+		}
+	}
+	return val, true
+}
+
+/**
+	Replaces existing child by the token
+*/
+
+func replaceChild(parent Value, token string, val Value) (Value, error) {
+	if _, ok := childOf(parent, token); !ok {
+		return nil, errors.Wrapf(ErrPathNotFound, "token '%s'", token)
+	}
+	switch parent.Kind() {
+	case MAP:
+		return parent.(Map).Put(token, val), nil
+	default:
+		i, _ := parseIndex(token)
+		return parent.(List).PutAt(i, val), nil
+	}
+}
+
+/**
+	Adds child by the token, inserts into the solidListValue, puts into Map or sparseListValue
+*/
+
+func addChild(parent Value, token string, val Value) (Value, error) {
+	if parent == nil {
+		return nil, errors.Wrapf(ErrPathNotFound, "token '%s'", token)
+	}
+	switch parent.Kind() {
+	case MAP:
+		return parent.(Map).Put(token, val), nil
+	case LIST:
+		list := parent.(List)
+		if token == "-" && !isSparseList(parent) {
+			return list.Append(val), nil
+		}
+		i, ok := parseIndex(token)
+		if !ok {
+			return nil, errors.Errorf("invalid list index '%s'", token)
+		}
+		if isSparseList(parent) {
+			return list.PutAt(i, val), nil
+		}
+		if i > list.Len() {
+			return nil, errors.Wrapf(ErrPathNotFound, "index %d out of bounds %d", i, list.Len())
+		}
+		return list.InsertAt(i, val), nil
+	default:
+		return nil, errors.Errorf("can not add child '%s' to %s", token, parent.Kind())
+	}
+}
+
+/**
+	Removes existing child by the token
+*/
+
+func removeChild(parent Value, token string) (Value, error) {
+	if _, ok := childOf(parent, token); !ok {
+		return nil, errors.Wrapf(ErrPathNotFound, "token '%s'", token)
+	}
+	switch parent.Kind() {
+	case MAP:
+		return parent.(Map).Remove(token), nil
+	default:
+		i, _ := parseIndex(token)
+		return parent.(List).RemoveAt(i), nil
+	}
+}
+
+/**
+	Rebuilds the path from root to the parent of the last token, shares untouched subtrees
+*/
+
+func updatePath(root Value, path []string, update func(parent Value, token string) (Value, error)) (Value, error) {
+	if len(path) == 0 {
+		return nil, errors.New("empty path")
+	}
+	if len(path) == 1 {
+		return update(root, path[0])
+	}
+	child, ok := childOf(root, path[0])
+	if !ok {
+		return nil, errors.Wrapf(ErrPathNotFound, "token '%s'", path[0])
+	}
+	newChild, err := updatePath(child, path[1:], update)
+	if err != nil {
+		return nil, err
+	}
+	return replaceChild(root, path[0], newChild)
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+func escapePointerToken(token string) string {
+	return pointerEscaper.Replace(token)
+}
+
+func unescapePointerToken(token string) string {
+	return pointerUnescaper.Replace(token)
+}
+
+/**
+	Parses JSON Pointer (RFC 6901) to tokens, empty pointer is the root
+*/
+
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, errors.Errorf("invalid JSON pointer '%s'", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = unescapePointerToken(token)
+	}
+	return tokens, nil
+}
+
+/**
+	Formats tokens as JSON Pointer (RFC 6901)
+*/
+
+func formatPointer(path []string) string {
+	var out strings.Builder
+	for _, token := range path {
+		out.WriteByte('/')
+		out.WriteString(escapePointerToken(token))
+	}
+	return out.String()
+}
+
+/**
+	Parses JSON Pointer "/a/b/3/c" or dotted path "a.b.3.c" to tokens
+
+	Dotted path has no escaping, use JSON Pointer for keys with dots.
+*/
+
+func parsePath(path string) ([]string, error) {
+	if path == "" || path[0] == '/' {
+		return parsePointer(path)
+	}
+	return strings.Split(path, "."), nil
+}
+
+/**
+	Puts child by the token, replaces existing one or adds a new one, "-" appends to the solidListValue
+*/
+
+func putChild(parent Value, token string, val Value) (Value, error) {
+	if parent != nil && parent.Kind() == LIST && !isSparseList(parent) {
+		list := parent.(List)
+		if token == "-" {
+			return list.Append(val), nil
+		}
+		i, ok := parseIndex(token)
+		if !ok {
+			return nil, errors.Errorf("invalid list index '%s'", token)
+		}
+		if i > list.Len() {
+			return nil, errors.Wrapf(ErrPathNotFound, "index %d out of bounds %d", i, list.Len())
+		}
+		return list.PutAt(i, val), nil
+	}
+	return addChild(parent, token, val)
+}
+
+/**
+	Gets value by JSON Pointer or dotted path
+
+	return value or nil if path not found
+*/
+
+func GetPath(root Value, path string) Value {
+	tokens, err := parsePath(path)
+	if err != nil {
+		return nil
+	}
+	val, _ := getPath(root, tokens)
+	return val
+}
+
+func GetPathBool(root Value, path string) Bool {
+	value := GetPath(root, path)
+	if !IsNull(value) {
+		if value.Kind() == BOOL {
+			return value.(Bool)
+		}
+		return ParseBoolean(value.String())
+	}
+	return nil
+}
+
+func GetPathNumber(root Value, path string) Number {
+	value := GetPath(root, path)
+	if !IsNull(value) {
+		if value.Kind() == NUMBER {
+			return value.(Number)
+		}
+		return ParseNumber(value.String())
+	}
+	return nil
+}
+
+func GetPathString(root Value, path string) String {
+	value := GetPath(root, path)
+	if !IsNull(value) {
+		if value.Kind() == STRING {
+			return value.(String)
+		}
+		return ParseString(value.String())
+	}
+	return nil
+}
+
+func GetPathList(root Value, path string) List {
+	value := GetPath(root, path)
+	if !IsNull(value) {
+		switch value.Kind() {
+		case LIST:
+			return value.(List)
+		case MAP:
+			return SolidList(value.(Map).Values())
+		}
+	}
+	return nil
+}
+
+func GetPathMap(root Value, path string) Map {
+	value := GetPath(root, path)
+	if !IsNull(value) {
+		switch value.Kind() {
+		case LIST:
+			return SortedMap(value.(List).Entries(), false)
+		case MAP:
+			return value.(Map)
+		}
+	}
+	return nil
+}
+
+func GetPathTime(root Value, path string) Time {
+	value := GetPath(root, path)
+	if !IsNull(value) {
+		if value.Kind() == TIME {
+			return value.(Time)
+		}
+		return ParseTime(value.String())
+	}
+	return nil
+}
+
+/**
+	Sets value by JSON Pointer or dotted path, returns a new root that shares untouched subtrees
+
+	Parent of the last token must exist, empty path replaces the root.
+*/
+
+func SetPath(root Value, path string, val Value) (Value, error) {
+	tokens, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return val, nil
+	}
+	return updatePath(root, tokens, func(parent Value, token string) (Value, error) {
+		return putChild(parent, token, val)
+	})
+}
+
+/**
+	Removes value by JSON Pointer or dotted path, returns a new root that shares untouched subtrees
+
+	Removing of the root returns nil.
+*/
+
+func RemovePath(root Value, path string) (Value, error) {
+	tokens, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	return updatePath(root, tokens, removeChild)
+}
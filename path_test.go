@@ -0,0 +1,86 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value_test
+
+import (
+	val "arpabet.pkg.is/value"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestGetPath(t *testing.T) {
+
+	m := testCreateMap().Put("a/b", val.EmptyMap().Put("m~n", val.Utf8("esc")))
+	m = m.Put("sparse", val.SparseListOf([]val.Value{val.Long(0)}).PutAt(7, val.EmptyMap().Put("c", val.Long(77))))
+
+	require.True(t, val.Equal(m, val.GetPath(m, "")))
+	require.Equal(t, "name", val.GetPathString(m, "/name").String())
+	require.Equal(t, "name", val.GetPathString(m, "name").String())
+	require.True(t, val.Equal(m.GetList("list").GetAt(2), val.GetPath(m, "/list/2")))
+	require.True(t, val.Equal(m.GetList("list").GetAt(2), val.GetPath(m, "list.2")))
+	require.Equal(t, "esc", val.GetPathString(m, "/a~1b/m~0n").String())
+	require.Equal(t, int64(77), val.GetPathNumber(m, "/sparse/7/c").Long())
+	require.NotNil(t, val.GetPathMap(m, "/sparse/7"))
+	require.NotNil(t, val.GetPathList(m, "/list"))
+
+	require.Nil(t, val.GetPath(m, "/sparse/3"))
+	require.Nil(t, val.GetPath(m, "/list/05"))
+	require.Nil(t, val.GetPath(m, "/list/100"))
+	require.Nil(t, val.GetPath(m, "/name/x"))
+	require.Nil(t, val.GetPathString(m, "/missing"))
+
+}
+
+func TestSetPath(t *testing.T) {
+
+	m := testCreateMap()
+	before := val.Jsonify(m)
+
+	actual, err := val.SetPath(m, "/list/1", val.Utf8("x"))
+	require.Nil(t, err)
+	require.Equal(t, "x", val.GetPathString(actual, "/list/1").String())
+	require.Equal(t, before, val.Jsonify(m))
+
+	actual, err = val.SetPath(actual, "list.-", val.True)
+	require.Nil(t, err)
+	require.Equal(t, 6, val.GetPathList(actual, "/list").Len())
+
+	actual, err = val.SetPath(actual, "/new", val.Long(1))
+	require.Nil(t, err)
+	require.Equal(t, int64(1), val.GetPathNumber(actual, "/new").Long())
+
+	_, err = val.SetPath(m, "/missing/x", val.Long(1))
+	require.True(t, errors.Is(err, val.ErrPathNotFound))
+	_, err = val.SetPath(m, "/list/100", val.Long(1))
+	require.True(t, errors.Is(err, val.ErrPathNotFound))
+
+	actual, err = val.RemovePath(m, "/list/0")
+	require.Nil(t, err)
+	require.Equal(t, 4, val.GetPathList(actual, "/list").Len())
+	require.Equal(t, before, val.Jsonify(m))
+
+	_, err = val.RemovePath(m, "/missing")
+	require.True(t, errors.Is(err, val.ErrPathNotFound))
+
+	actual, err = val.RemovePath(m, "")
+	require.Nil(t, err)
+	require.Nil(t, actual)
+
+}
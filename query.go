@@ -0,0 +1,881 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+/**
+	JSONPath query over the Value tree
+
+	Supported syntax:
+		$                   root
+		.name ['name']      Map value by key
+		.* [*]              all children of Map or List
+		..name ..* ..[0]    recursive descent
+		[0] [-1] [0,'a']    List element by index, union of selectors
+		[start:end:step]    slice of List
+		[?(@.qty > 0)]      filter by == != < <= > >= && || ! and existence of @.path
+
+	For sparseListValue index is a key, negative indexes and slices count from the last key + 1.
+	Compiled Query is immutable and safe for concurrent use.
+*/
+
+type Query struct {
+	expr     string
+	segments []querySegment
+}
+
+type querySegment struct {
+	recursive bool
+	selectors []querySelector
+}
+
+type queryNode struct {
+	path  []string
+	value Value
+}
+
+type querySelector interface {
+	apply(node queryNode, root Value, emit func(queryNode))
+}
+
+/**
+	Compiles JSONPath expression
+*/
+
+func CompileQuery(expr string) (*Query, error) {
+	p := &queryParser{expr: expr}
+	segments, err := p.parseQuery()
+	if err != nil {
+		return nil, err
+	}
+	return &Query{expr: expr, segments: segments}, nil
+}
+
+/**
+	Compiles JSONPath expression, panics on error
+*/
+
+func MustCompileQuery(expr string) *Query {
+	q, err := CompileQuery(expr)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+func (q *Query) String() string {
+	return q.expr
+}
+
+/**
+	Selects values in the document order
+*/
+
+func (q *Query) Select(root Value) []Value {
+	var out []Value
+	q.eval(root, func(node queryNode) {
+		out = append(out, node.value)
+	})
+	return out
+}
+
+/**
+	Selects JSON Pointers (RFC 6901) of values in the document order
+*/
+
+func (q *Query) SelectPaths(root Value) []string {
+	var out []string
+	q.eval(root, func(node queryNode) {
+		out = append(out, formatPointer(node.path))
+	})
+	return out
+}
+
+func (q *Query) eval(root Value, emit func(queryNode)) {
+	nodes := []queryNode{{value: root}}
+	for _, seg := range q.segments {
+		var next []queryNode
+		add := func(node queryNode) {
+			next = append(next, node)
+		}
+		visit := func(node queryNode) {
+			for _, s := range seg.selectors {
+				s.apply(node, root, add)
+			}
+		}
+		for _, node := range nodes {
+			if seg.recursive {
+				queryDescend(node, visit)
+			} else {
+				visit(node)
+			}
+		}
+		nodes = next
+	}
+	for _, node := range nodes {
+		emit(node)
+	}
+}
+
+func queryChild(node queryNode, token string, val Value) queryNode {
+	n := len(node.path)
+	return queryNode{path: append(node.path[:n:n], token), value: val}
+}
+
+func queryChildren(node queryNode, emit func(queryNode)) {
+	if node.value == nil {
+		return
+	}
+	switch node.value.Kind() {
+	case MAP:
+		for _, e := range node.value.(Map).Entries() {
+			emit(queryChild(node, e.Key(), e.Value()))
+		}
+	case LIST:
+		if isSparseList(node.value) {
+			for _, item := range node.value.(List).Items() {
+				emit(queryChild(node, strconv.Itoa(item.Key()), item.Value()))
+			}
+		} else {
+			for i, val := range node.value.(List).Values() {
+				emit(queryChild(node, strconv.Itoa(i), val))
+			}
+		}
+	}
+}
+
+func queryDescend(node queryNode, visit func(queryNode)) {
+	visit(node)
+	queryChildren(node, func(child queryNode) {
+		queryDescend(child, visit)
+	})
+}
+
+/**
+	Length of the List for negative indexes, for sparseListValue it is the last key + 1
+*/
+
+func queryListLen(list List) int {
+	if isSparseList(list) {
+		items := list.Items()
+		if len(items) == 0 {
+			return 0
+		}
+		return items[len(items)-1].Key() + 1
+	}
+	return list.Len()
+}
+
+type queryName struct {
+	name string
+}
+
+func (s queryName) apply(node queryNode, root Value, emit func(queryNode)) {
+	if node.value != nil && node.value.Kind() == MAP {
+		if val, ok := node.value.(Map).Get(s.name); ok {
+			emit(queryChild(node, s.name, val))
+		}
+	}
+}
+
+type queryWildcard struct {
+}
+
+func (s queryWildcard) apply(node queryNode, root Value, emit func(queryNode)) {
+	queryChildren(node, emit)
+}
+
+type queryIndex struct {
+	index int
+}
+
+func (s queryIndex) apply(node queryNode, root Value, emit func(queryNode)) {
+	if node.value == nil || node.value.Kind() != LIST {
+		return
+	}
+	i := s.index
+	if i < 0 {
+		i += queryListLen(node.value.(List))
+	}
+	token := strconv.Itoa(i)
+	if val, ok := childOf(node.value, token); ok {
+		emit(queryChild(node, token, val))
+	}
+}
+
+type querySlice struct {
+	start, end, step int
+	hasStart, hasEnd bool
+}
+
+/**
+	Normalized bounds of the slice as in RFC 9535
+*/
+
+func (s querySlice) bounds(n int) (lower, upper int) {
+	normalize := func(i int) int {
+		if i < 0 {
+			return n + i
+		}
+		return i
+	}
+	clamp := func(i, min, max int) int {
+		if i < min {
+			return min
+		}
+		if i > max {
+			return max
+		}
+		return i
+	}
+	start, end := s.start, s.end
+	if s.step >= 0 {
+		if !s.hasStart {
+			start = 0
+		}
+		if !s.hasEnd {
+			end = n
+		}
+		return clamp(normalize(start), 0, n), clamp(normalize(end), 0, n)
+	}
+	if !s.hasStart {
+		start = n - 1
+	}
+	if !s.hasEnd {
+		end = -n - 1
+	}
+	return clamp(normalize(end), -1, n-1), clamp(normalize(start), -1, n-1)
+}
+
+func (s querySlice) contains(i, lower, upper int) bool {
+	if s.step > 0 {
+		return lower <= i && i < upper && (i-lower)%s.step == 0
+	}
+	return lower < i && i <= upper && (upper-i)%(-s.step) == 0
+}
+
+func (s querySlice) apply(node queryNode, root Value, emit func(queryNode)) {
+	if s.step == 0 || node.value == nil || node.value.Kind() != LIST {
+		return
+	}
+	lower, upper := s.bounds(queryListLen(node.value.(List)))
+	var children []queryNode
+	queryChildren(node, func(child queryNode) {
+		i, _ := strconv.Atoi(child.path[len(child.path)-1])
+		if s.contains(i, lower, upper) {
+			children = append(children, child)
+		}
+	})
+	if s.step > 0 {
+		for _, child := range children {
+			emit(child)
+		}
+	} else {
+		for i := len(children) - 1; i >= 0; i-- {
+			emit(children[i])
+		}
+	}
+}
+
+type queryFilter struct {
+	cond queryCond
+}
+
+func (s queryFilter) apply(node queryNode, root Value, emit func(queryNode)) {
+	queryChildren(node, func(child queryNode) {
+		if s.cond.test(child.value, root) {
+			emit(child)
+		}
+	})
+}
+
+/**
+	Filter expressions
+*/
+
+type queryCond interface {
+	test(current, root Value) bool
+}
+
+type queryOperand interface {
+	eval(current, root Value) (Value, bool)
+}
+
+type queryPath struct {
+	fromRoot bool
+	path     []string
+}
+
+func (o queryPath) eval(current, root Value) (Value, bool) {
+	if o.fromRoot {
+		return getPath(root, o.path)
+	}
+	return getPath(current, o.path)
+}
+
+type queryLiteral struct {
+	value Value
+}
+
+func (o queryLiteral) eval(current, root Value) (Value, bool) {
+	return o.value, true
+}
+
+type queryExists struct {
+	operand queryOperand
+}
+
+func (c queryExists) test(current, root Value) bool {
+	_, ok := c.operand.eval(current, root)
+	return ok
+}
+
+type queryNot struct {
+	cond queryCond
+}
+
+func (c queryNot) test(current, root Value) bool {
+	return !c.cond.test(current, root)
+}
+
+type queryAnd struct {
+	left, right queryCond
+}
+
+func (c queryAnd) test(current, root Value) bool {
+	return c.left.test(current, root) && c.right.test(current, root)
+}
+
+type queryOr struct {
+	left, right queryCond
+}
+
+func (c queryOr) test(current, root Value) bool {
+	return c.left.test(current, root) || c.right.test(current, root)
+}
+
+type queryCompare struct {
+	op          string
+	left, right queryOperand
+}
+
+func (c queryCompare) test(current, root Value) bool {
+	a, aok := c.left.eval(current, root)
+	b, bok := c.right.eval(current, root)
+	switch c.op {
+	case "==":
+		return queryEqual(a, aok, b, bok)
+	case "!=":
+		return !queryEqual(a, aok, b, bok)
+	case "<":
+		return aok && bok && queryLess(a, b)
+	case "<=":
+		return aok && bok && (queryLess(a, b) || queryEqual(a, aok, b, bok))
+	case ">":
+		return aok && bok && queryLess(b, a)
+	case ">=":
+		return aok && bok && (queryLess(b, a) || queryEqual(a, aok, b, bok))
+	default:
+		return false
+	}
+}
+
+/**
+	Missing values are equal to each other, numbers are compared by value regardless of the type
+*/
+
+func queryEqual(a Value, aok bool, b Value, bok bool) bool {
+	if !aok || !bok {
+		return aok == bok
+	}
+	if IsNull(a) || IsNull(b) {
+		return IsNull(a) && IsNull(b)
+	}
+	if a.Kind() == NUMBER && b.Kind() == NUMBER {
+		cmp, ok := queryCompareNumbers(a.(Number), b.(Number))
+		return ok && cmp == 0
+	}
+	return Equal(a, b)
+}
+
+/**
+	Only numbers and strings are ordered
+*/
+
+func queryLess(a, b Value) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	switch {
+	case a.Kind() == NUMBER && b.Kind() == NUMBER:
+		cmp, ok := queryCompareNumbers(a.(Number), b.(Number))
+		return ok && cmp < 0
+	case a.Kind() == STRING && b.Kind() == STRING:
+		return a.String() < b.String()
+	default:
+		return false
+	}
+}
+
+func queryCompareNumbers(a, b Number) (int, bool) {
+	if a.IsNaN() || b.IsNaN() {
+		return 0, false
+	}
+	return compareNumbers(a, b), true
+}
+
+/**
+	Parser of JSONPath expression
+*/
+
+type queryParser struct {
+	expr string
+	pos  int
+}
+
+func (p *queryParser) errorf(format string, args ...interface{}) error {
+	return errors.Errorf("invalid query '%s' at %d, %s", p.expr, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *queryParser) eof() bool {
+	return p.pos >= len(p.expr)
+}
+
+func (p *queryParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.expr[p.pos]
+}
+
+func (p *queryParser) hasPrefix(prefix string) bool {
+	return strings.HasPrefix(p.expr[p.pos:], prefix)
+}
+
+func (p *queryParser) skipSpaces() {
+	for !p.eof() && (p.expr[p.pos] == ' ' || p.expr[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *queryParser) expect(ch byte) error {
+	p.skipSpaces()
+	if p.peek() != ch {
+		return p.errorf("expected '%c'", ch)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *queryParser) parseQuery() ([]querySegment, error) {
+	p.skipSpaces()
+	if p.peek() != '$' {
+		return nil, p.errorf("expected '$'")
+	}
+	p.pos++
+	var segments []querySegment
+	for {
+		p.skipSpaces()
+		if p.eof() {
+			return segments, nil
+		}
+		var seg querySegment
+		switch {
+		case p.hasPrefix(".."):
+			p.pos += 2
+			seg.recursive = true
+			if p.peek() == '[' {
+				selectors, err := p.parseBracket()
+				if err != nil {
+					return nil, err
+				}
+				seg.selectors = selectors
+			} else {
+				selector, err := p.parseDotSelector()
+				if err != nil {
+					return nil, err
+				}
+				seg.selectors = []querySelector{selector}
+			}
+		case p.peek() == '.':
+			p.pos++
+			selector, err := p.parseDotSelector()
+			if err != nil {
+				return nil, err
+			}
+			seg.selectors = []querySelector{selector}
+		case p.peek() == '[':
+			selectors, err := p.parseBracket()
+			if err != nil {
+				return nil, err
+			}
+			seg.selectors = selectors
+		default:
+			return nil, p.errorf("unexpected character '%c'", p.peek())
+		}
+		segments = append(segments, seg)
+	}
+}
+
+func (p *queryParser) parseDotSelector() (querySelector, error) {
+	if p.peek() == '*' {
+		p.pos++
+		return queryWildcard{}, nil
+	}
+	name, err := p.parseName()
+	if err != nil {
+		return nil, err
+	}
+	return queryName{name}, nil
+}
+
+func isQueryNameChar(ch byte) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' || ch == '_' || ch == '-' || ch >= utf8.RuneSelf
+}
+
+func (p *queryParser) parseName() (string, error) {
+	start := p.pos
+	for !p.eof() && isQueryNameChar(p.expr[p.pos]) {
+		p.pos++
+	}
+	if start == p.pos {
+		return "", p.errorf("expected name")
+	}
+	return p.expr[start:p.pos], nil
+}
+
+func (p *queryParser) parseBracket() ([]querySelector, error) {
+	p.pos++
+	var selectors []querySelector
+	for {
+		p.skipSpaces()
+		selector, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, selector)
+		p.skipSpaces()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return selectors, nil
+		default:
+			return nil, p.errorf("expected ',' or ']'")
+		}
+	}
+}
+
+func (p *queryParser) parseSelector() (querySelector, error) {
+	switch ch := p.peek(); {
+	case ch == '*':
+		p.pos++
+		return queryWildcard{}, nil
+	case ch == '\'' || ch == '"':
+		name, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return queryName{name}, nil
+	case ch == '?':
+		p.pos++
+		cond, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return queryFilter{cond}, nil
+	case ch == '-' || ch == ':' || ch >= '0' && ch <= '9':
+		return p.parseIndexOrSlice()
+	default:
+		return nil, p.errorf("expected selector")
+	}
+}
+
+func (p *queryParser) parseInt() (int, bool, error) {
+	p.skipSpaces()
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	for !p.eof() && p.expr[p.pos] >= '0' && p.expr[p.pos] <= '9' {
+		p.pos++
+	}
+	if start == p.pos {
+		return 0, false, nil
+	}
+	i, err := strconv.Atoi(p.expr[start:p.pos])
+	if err != nil {
+		return 0, false, p.errorf("invalid integer '%s'", p.expr[start:p.pos])
+	}
+	return i, true, nil
+}
+
+func (p *queryParser) parseIndexOrSlice() (querySelector, error) {
+	start, hasStart, err := p.parseInt()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.peek() != ':' {
+		if !hasStart {
+			return nil, p.errorf("expected index")
+		}
+		return queryIndex{start}, nil
+	}
+	p.pos++
+	s := querySlice{start: start, hasStart: hasStart, step: 1}
+	if s.end, s.hasEnd, err = p.parseInt(); err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.peek() == ':' {
+		p.pos++
+		step, hasStep, err := p.parseInt()
+		if err != nil {
+			return nil, err
+		}
+		if hasStep {
+			s.step = step
+		}
+	}
+	return s, nil
+}
+
+func (p *queryParser) parseString() (string, error) {
+	quote := p.expr[p.pos]
+	p.pos++
+	var out strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+		ch := p.expr[p.pos]
+		p.pos++
+		switch ch {
+		case quote:
+			return out.String(), nil
+		case '\\':
+			if p.eof() {
+				return "", p.errorf("unterminated string")
+			}
+			esc := p.expr[p.pos]
+			p.pos++
+			switch esc {
+			case 'b':
+				out.WriteByte('\b')
+			case 'f':
+				out.WriteByte('\f')
+			case 'n':
+				out.WriteByte('\n')
+			case 'r':
+				out.WriteByte('\r')
+			case 't':
+				out.WriteByte('\t')
+			case 'u':
+				if p.pos+4 > len(p.expr) {
+					return "", p.errorf("invalid unicode escape")
+				}
+				r, err := strconv.ParseUint(p.expr[p.pos:p.pos+4], 16, 32)
+				if err != nil {
+					return "", p.errorf("invalid unicode escape")
+				}
+				p.pos += 4
+				out.WriteRune(rune(r))
+			default:
+				out.WriteByte(esc)
+			}
+		default:
+			out.WriteByte(ch)
+		}
+	}
+}
+
+func (p *queryParser) parseOr() (queryCond, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpaces()
+		if !p.hasPrefix("||") {
+			return left, nil
+		}
+		p.pos += 2
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = queryOr{left, right}
+	}
+}
+
+func (p *queryParser) parseAnd() (queryCond, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpaces()
+		if !p.hasPrefix("&&") {
+			return left, nil
+		}
+		p.pos += 2
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = queryAnd{left, right}
+	}
+}
+
+func (p *queryParser) parseUnary() (queryCond, error) {
+	p.skipSpaces()
+	switch p.peek() {
+	case '!':
+		p.pos++
+		cond, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return queryNot{cond}, nil
+	case '(':
+		p.pos++
+		cond, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(')'); err != nil {
+			return nil, err
+		}
+		return cond, nil
+	default:
+		return p.parseComparison()
+	}
+}
+
+var queryCompareOps = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+func (p *queryParser) parseComparison() (queryCond, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	for _, op := range queryCompareOps {
+		if p.hasPrefix(op) {
+			p.pos += len(op)
+			right, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			return queryCompare{op: op, left: left, right: right}, nil
+		}
+	}
+	if _, ok := left.(queryPath); !ok {
+		return nil, p.errorf("expected comparison")
+	}
+	return queryExists{left}, nil
+}
+
+func (p *queryParser) parseOperand() (queryOperand, error) {
+	p.skipSpaces()
+	switch ch := p.peek(); {
+	case ch == '@' || ch == '$':
+		p.pos++
+		return p.parsePath(ch == '$')
+	case ch == '\'' || ch == '"':
+		str, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return queryLiteral{Utf8(str)}, nil
+	case p.hasPrefix("true"):
+		p.pos += 4
+		return queryLiteral{True}, nil
+	case p.hasPrefix("false"):
+		p.pos += 5
+		return queryLiteral{False}, nil
+	case p.hasPrefix("null"):
+		p.pos += 4
+		return queryLiteral{nil}, nil
+	case ch == '-' || ch >= '0' && ch <= '9':
+		start := p.pos
+		p.pos++
+		for !p.eof() && strings.IndexByte("0123456789.eE+-", p.expr[p.pos]) != -1 {
+			p.pos++
+		}
+		num := ParseNumber(p.expr[start:p.pos])
+		if num.IsNaN() {
+			return nil, p.errorf("invalid number '%s'", p.expr[start:p.pos])
+		}
+		return queryLiteral{num}, nil
+	default:
+		return nil, p.errorf("expected operand")
+	}
+}
+
+func (p *queryParser) parsePath(fromRoot bool) (queryOperand, error) {
+	op := queryPath{fromRoot: fromRoot}
+	for {
+		switch {
+		case p.hasPrefix(".."):
+			return nil, p.errorf("recursive descent is not supported in filter")
+		case p.peek() == '.':
+			p.pos++
+			name, err := p.parseName()
+			if err != nil {
+				return nil, err
+			}
+			op.path = append(op.path, name)
+		case p.peek() == '[':
+			p.pos++
+			p.skipSpaces()
+			switch ch := p.peek(); {
+			case ch == '\'' || ch == '"':
+				name, err := p.parseString()
+				if err != nil {
+					return nil, err
+				}
+				op.path = append(op.path, name)
+			default:
+				i, ok, err := p.parseInt()
+				if err != nil {
+					return nil, err
+				}
+				if !ok || i < 0 {
+					return nil, p.errorf("expected key or non-negative index")
+				}
+				op.path = append(op.path, strconv.Itoa(i))
+			}
+			if err := p.expect(']'); err != nil {
+				return nil, err
+			}
+		default:
+			return op, nil
+		}
+	}
+}
@@ -0,0 +1,132 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value_test
+
+import (
+	val "arpabet.pkg.is/value"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"testing"
+)
+
+func testQueryDoc(t *testing.T) val.Value {
+	doc, err := val.ParseJSON([]byte(`{
+		"store": {
+			"items": [
+				{"name": "apple", "price": 1.5, "qty": 3},
+				{"name": "pear", "price": 2, "qty": 0},
+				{"name": "plum", "price": 0.75, "qty": 10, "tags": ["ripe"]}
+			],
+			"owner": {"name": "bob", "price": 100}
+		},
+		"active": true
+	}`))
+	require.Nil(t, err)
+	return doc
+}
+
+func testQuery(t *testing.T, doc val.Value, expr string) []string {
+	q, err := val.CompileQuery(expr)
+	require.Nil(t, err, expr)
+	values := q.Select(doc)
+	paths := q.SelectPaths(doc)
+	require.Equal(t, len(values), len(paths))
+	for i, p := range paths {
+		require.True(t, val.Equal(values[i], val.GetPath(doc, p)), p)
+	}
+	return paths
+}
+
+func TestQuery(t *testing.T) {
+
+	doc := testQueryDoc(t)
+
+	require.Equal(t, []string{""}, testQuery(t, doc, "$"))
+	require.Equal(t, []string{"/store/owner/name"}, testQuery(t, doc, "$.store.owner.name"))
+	require.Equal(t, []string{"/store/owner/name"}, testQuery(t, doc, "$['store'][\"owner\"]['name']"))
+	require.Equal(t, []string{"/store/items/0/name", "/store/items/1/name", "/store/items/2/name"}, testQuery(t, doc, "$.store.items[*].name"))
+	require.Equal(t, []string{"/store/items/0/price", "/store/items/1/price", "/store/items/2/price", "/store/owner/price"}, testQuery(t, doc, "$..price"))
+	require.Equal(t, []string{"/store/items/2"}, testQuery(t, doc, "$.store.items[-1]"))
+	require.Equal(t, []string{"/store/items/0", "/store/items/2"}, testQuery(t, doc, "$.store.items[0, 2]"))
+	require.Equal(t, []string{"/store/items/0", "/store/items/1"}, testQuery(t, doc, "$.store.items[:2]"))
+	require.Equal(t, []string{"/store/items/2", "/store/items/0"}, testQuery(t, doc, "$.store.items[::-2]"))
+	require.Equal(t, []string{"/active", "/store"}, testQuery(t, doc, "$.*"))
+	require.Nil(t, testQuery(t, doc, "$.missing[0]"))
+
+}
+
+func TestQueryFilter(t *testing.T) {
+
+	doc := testQueryDoc(t)
+
+	require.Equal(t, []string{"/store/items/0/price", "/store/items/2/price"}, testQuery(t, doc, "$..items[?(@.qty > 0)].price"))
+	require.Equal(t, []string{"/store/items/1"}, testQuery(t, doc, "$.store.items[?@.price == 2.0]"))
+	require.Equal(t, []string{"/store/items/2"}, testQuery(t, doc, "$.store.items[?(@.tags)]"))
+	require.Equal(t, []string{"/store/items/0", "/store/items/1"}, testQuery(t, doc, "$.store.items[?(!@.tags)]"))
+	require.Equal(t, []string{"/store/items/0"}, testQuery(t, doc, "$.store.items[?(@.name == 'apple' || @.qty >= 100)]"))
+	require.Equal(t, []string{"/store/items/2"}, testQuery(t, doc, "$.store.items[?(@.name > 'pear' && $.active == true)]"))
+	require.Equal(t, []string{"/store/items/2"}, testQuery(t, doc, "$.store.items[?(@.tags[0] == \"ripe\")]"))
+	require.Equal(t, []string{"/store/owner", "/store/items/0", "/store/items/2"}, testQuery(t, doc, "$..[?(@.price && @.price != 2)]"))
+
+}
+
+func TestQuerySparseList(t *testing.T) {
+
+	list := val.SparseListOf([]val.Value{val.Long(0)}).PutAt(3, val.Long(3)).PutAt(7, val.Long(7))
+
+	require.Equal(t, []string{"/3"}, testQuery(t, list, "$[3]"))
+	require.Nil(t, testQuery(t, list, "$[2]"))
+	require.Equal(t, []string{"/7"}, testQuery(t, list, "$[-1]"))
+	require.Equal(t, []string{"/3", "/7"}, testQuery(t, list, "$[1:]"))
+	require.Equal(t, []string{"/0", "/3", "/7"}, testQuery(t, list, "$[*]"))
+	require.Equal(t, []string{"/7"}, testQuery(t, list, "$[?(@ > 5)]"))
+
+}
+
+func TestQueryErrors(t *testing.T) {
+
+	for _, expr := range []string{"", "store", "$.", "$[", "$[1", "$['a]", "$[?(@.a ==)]", "$[?(1)]", "$[?(@..a)]", "$x"} {
+		_, err := val.CompileQuery(expr)
+		require.NotNil(t, err, expr)
+	}
+
+	require.Panics(t, func() {
+		val.MustCompileQuery("$[")
+	})
+
+}
+
+func TestQueryConcurrent(t *testing.T) {
+
+	doc := testQueryDoc(t)
+	q := val.MustCompileQuery("$..items[?(@.qty > 0)].name")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				require.Equal(t, 2, len(q.Select(doc)))
+			}
+		}()
+	}
+	wg.Wait()
+
+}
@@ -0,0 +1,274 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value
+
+import (
+	"github.com/shopspring/decimal"
+	"math"
+	"math/big"
+)
+
+/**
+	Decimal rounding and precision
+
+	All results of NumberContext are DECIMAL numbers with the exponent fully defined by
+	the input and the context, so the packed bytes of the result are deterministic.
+	NaN and infinite DOUBLE or FLOAT numbers are returned as is.
+*/
+
+type RoundingMode int
+
+const (
+	RoundHalfEven RoundingMode = iota
+	RoundHalfUp
+	RoundFloor
+	RoundCeiling
+	RoundTruncate
+)
+
+func (m RoundingMode) String() string {
+	switch m {
+	case RoundHalfEven:
+		return "half_even"
+	case RoundHalfUp:
+		return "half_up"
+	case RoundFloor:
+		return "floor"
+	case RoundCeiling:
+		return "ceiling"
+	case RoundTruncate:
+		return "truncate"
+	default:
+		return "unknown"
+	}
+}
+
+/**
+	Number of significant digits of decimal128
+*/
+
+const DefaultPrecision = 34
+
+type NumberContext struct {
+
+	/**
+		Number of significant digits, zero is unlimited except Divide that uses DefaultPrecision
+	*/
+
+	Precision int
+
+	/**
+		RoundHalfEven is banker's rounding, RoundHalfUp rounds half away from zero,
+		RoundFloor rounds toward negative infinity, RoundCeiling toward positive infinity, RoundTruncate toward zero
+	*/
+
+	Rounding RoundingMode
+}
+
+var DefaultNumberContext = NumberContext{Precision: DefaultPrecision, Rounding: RoundHalfEven}
+
+func isFiniteNumber(n Number) bool {
+	return !n.IsNaN() && !(isFloatingType(n.Type()) && math.IsInf(n.Double(), 0))
+}
+
+/**
+	Rounds the number to the scale digits after the decimal point, negative scale rounds to tens, hundreds and so on
+*/
+
+func (c NumberContext) Round(n Number, scale int32) Number {
+	if !isFiniteNumber(n) {
+		return n
+	}
+	return Decimal(c.rescale(n.Decimal(), -scale))
+}
+
+/**
+	Rounds the number to the same exponent as the pattern, Quantize(n, "0.01") rounds to cents
+*/
+
+func (c NumberContext) Quantize(n, pattern Number) Number {
+	if !isFiniteNumber(n) {
+		return n
+	}
+	if !isFiniteNumber(pattern) {
+		return Nan()
+	}
+	return Decimal(c.rescale(n.Decimal(), pattern.Decimal().Exponent()))
+}
+
+/**
+	Rounds the number to the precision of the context
+*/
+
+func (c NumberContext) Apply(n Number) Number {
+	if !isFiniteNumber(n) {
+		return n
+	}
+	return Decimal(c.applyPrecision(n.Decimal(), c.Precision))
+}
+
+/**
+	Divides numbers and rounds the quotient to the precision of the context, division by zero returns NaN
+
+	Trailing zeros of the quotient are removed up to the exponent a.exp - b.exp.
+*/
+
+func (c NumberContext) Divide(a, b Number) Number {
+	if a.IsNaN() || b.IsNaN() || b.Sign() == 0 {
+		return Nan()
+	}
+	if !isFiniteNumber(a) || !isFiniteNumber(b) {
+		return Double(a.Double() / b.Double())
+	}
+	p := c.Precision
+	if p <= 0 {
+		p = DefaultPrecision
+	}
+	x, y := a.Decimal(), b.Decimal()
+	ca, cb := x.Coefficient(), y.Coefficient()
+	ideal := int64(x.Exponent()) - int64(y.Exponent())
+	if ca.Sign() == 0 {
+		return Decimal(decimal.NewFromBigInt(ca, int32(ideal)))
+	}
+	target := ideal + int64(countDigits(ca)) - int64(countDigits(cb)) - int64(p)
+	for {
+		num, den := scaleQuotient(ca, cb, ideal-target)
+		q := roundQuotient(num, den, c.Rounding)
+		if countDigits(q) > p {
+			target++
+			continue
+		}
+		return Decimal(trimDecimal(q, target, ideal))
+	}
+}
+
+/**
+	Sums numbers of the list exactly and rounds the result to the precision of the context, non-numbers are skipped
+*/
+
+func (c NumberContext) Sum(list List) Number {
+	sum := decimal.Zero
+	for _, item := range list.Values() {
+		if item == nil || item.Kind() != NUMBER {
+			continue
+		}
+		n := item.(Number)
+		if !isFiniteNumber(n) {
+			return Nan()
+		}
+		sum = sum.Add(n.Decimal())
+	}
+	return Decimal(c.applyPrecision(sum, c.Precision))
+}
+
+func (c NumberContext) rescale(d decimal.Decimal, exp int32) decimal.Decimal {
+	num, den := scaleQuotient(d.Coefficient(), big.NewInt(1), int64(d.Exponent())-int64(exp))
+	return decimal.NewFromBigInt(roundQuotient(num, den, c.Rounding), exp)
+}
+
+func (c NumberContext) applyPrecision(d decimal.Decimal, p int) decimal.Decimal {
+	coef := d.Coefficient()
+	if p <= 0 || countDigits(coef) <= p {
+		return d
+	}
+	exp := int64(d.Exponent()) + int64(countDigits(coef)-p)
+	for {
+		num, den := scaleQuotient(coef, big.NewInt(1), int64(d.Exponent())-exp)
+		q := roundQuotient(num, den, c.Rounding)
+		if countDigits(q) > p {
+			exp++
+			continue
+		}
+		return decimal.NewFromBigInt(q, int32(exp))
+	}
+}
+
+func pow10(n int64) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(n), nil)
+}
+
+func countDigits(x *big.Int) int {
+	if x.Sign() == 0 {
+		return 1
+	}
+	return len(new(big.Int).Abs(x).String())
+}
+
+/**
+	Returns num/den equal to a/b * 10^shift
+*/
+
+func scaleQuotient(a, b *big.Int, shift int64) (num, den *big.Int) {
+	if shift >= 0 {
+		return new(big.Int).Mul(a, pow10(shift)), b
+	}
+	return a, new(big.Int).Mul(b, pow10(-shift))
+}
+
+/**
+	Rounds num/den to the integer by the rounding mode
+*/
+
+func roundQuotient(num, den *big.Int, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	neg := (num.Sign() < 0) != (den.Sign() < 0)
+	var away bool
+	switch mode {
+	case RoundFloor:
+		away = neg
+	case RoundCeiling:
+		away = !neg
+	case RoundTruncate:
+		away = false
+	default:
+		half := new(big.Int).Abs(r)
+		half.Lsh(half, 1)
+		cmp := half.Cmp(new(big.Int).Abs(den))
+		away = cmp > 0 || (cmp == 0 && (mode == RoundHalfUp || q.Bit(0) == 1))
+	}
+	if away {
+		if neg {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return q
+}
+
+/**
+	Removes trailing zeros of the coefficient while exponent is less than ideal one
+*/
+
+func trimDecimal(coef *big.Int, exp, ideal int64) decimal.Decimal {
+	ten := big.NewInt(10)
+	q, r := new(big.Int), new(big.Int)
+	for exp < ideal {
+		q.QuoRem(coef, ten, r)
+		if r.Sign() != 0 {
+			break
+		}
+		coef, q = q, coef
+		exp++
+	}
+	return decimal.NewFromBigInt(coef, int32(exp))
+}
@@ -0,0 +1,108 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value_test
+
+import (
+	val "arpabet.pkg.is/value"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func testDecimal(s string) val.Number {
+	return val.Decimal(decimal.RequireFromString(s))
+}
+
+func TestRoundingModes(t *testing.T) {
+
+	cases := []struct {
+		in       string
+		expected [5]string
+	}{
+		{"2.345", [5]string{"2.34", "2.35", "2.34", "2.35", "2.34"}},
+		{"2.355", [5]string{"2.36", "2.36", "2.35", "2.36", "2.35"}},
+		{"-2.345", [5]string{"-2.34", "-2.35", "-2.35", "-2.34", "-2.34"}},
+		{"2.3451", [5]string{"2.35", "2.35", "2.34", "2.35", "2.34"}},
+		{"2", [5]string{"2", "2", "2", "2", "2"}},
+	}
+
+	modes := []val.RoundingMode{val.RoundHalfEven, val.RoundHalfUp, val.RoundFloor, val.RoundCeiling, val.RoundTruncate}
+
+	for _, c := range cases {
+		for i, mode := range modes {
+			ctx := val.NumberContext{Rounding: mode}
+			actual := ctx.Round(testDecimal(c.in), 2)
+			require.Equal(t, val.DECIMAL, actual.Type())
+			require.Equal(t, int32(-2), actual.Decimal().Exponent(), "%s %s", c.in, mode)
+			require.True(t, testDecimal(c.expected[i]).Equal(actual), "%s %s: %s", c.in, mode, actual.Decimal())
+		}
+	}
+
+	require.Equal(t, "1200", val.DefaultNumberContext.Round(val.Long(1250), -2).Decimal().String())
+	require.Equal(t, "2.4", val.DefaultNumberContext.Round(val.Double(2.45), 1).Decimal().String())
+	require.True(t, val.DefaultNumberContext.Round(val.Nan(), 2).IsNaN())
+
+}
+
+func TestQuantize(t *testing.T) {
+
+	ctx := val.NumberContext{Rounding: val.RoundHalfUp}
+
+	a := ctx.Quantize(testDecimal("10.005"), testDecimal("0.01"))
+	b := ctx.Quantize(val.Double(10.01), testDecimal("0.01"))
+	require.Equal(t, "10.01", a.Decimal().String())
+
+	ab, err := a.MarshalBinary()
+	require.Nil(t, err)
+	bb, err := b.MarshalBinary()
+	require.Nil(t, err)
+	require.Equal(t, ab, bb)
+
+}
+
+func TestContextDivide(t *testing.T) {
+
+	ctx := val.NumberContext{Precision: 5, Rounding: val.RoundHalfEven}
+
+	require.Equal(t, "0.33333", ctx.Divide(val.Long(1), val.Long(3)).Decimal().String())
+	require.Equal(t, "0.66667", ctx.Divide(val.Long(2), val.Long(3)).Decimal().String())
+	require.Equal(t, "2.5", ctx.Divide(val.Long(10), val.Long(4)).Decimal().String())
+	require.Equal(t, "100", ctx.Divide(val.Long(100), val.Long(1)).Decimal().String())
+	require.Equal(t, "3333300", ctx.Divide(val.Long(9999900), val.Long(3)).Decimal().String())
+	require.Equal(t, "1.0000", ctx.Divide(testDecimal("9.99999"), val.Long(10)).Decimal().StringFixed(4))
+	require.Equal(t, "-0.33334", val.NumberContext{Precision: 5, Rounding: val.RoundFloor}.Divide(val.Long(-1), val.Long(3)).Decimal().String())
+	require.True(t, ctx.Divide(val.Long(1), val.Long(0)).IsNaN())
+	require.Equal(t, "0", ctx.Divide(val.Long(0), val.Long(7)).Decimal().String())
+
+	q := val.DefaultNumberContext.Divide(val.Long(1), val.Long(7))
+	require.Equal(t, 36, len(q.Decimal().String()))
+
+}
+
+func TestContextSum(t *testing.T) {
+
+	list := val.Tuple(testDecimal("0.1"), val.Double(0.2), val.Long(1), val.Utf8("skip"), nil)
+
+	require.Equal(t, "1.3", val.DefaultNumberContext.Sum(list).Decimal().String())
+	require.Equal(t, "1", val.NumberContext{Precision: 1}.Sum(list).Decimal().String())
+	require.Equal(t, "1.3", val.NumberContext{}.Apply(testDecimal("1.3")).Decimal().String())
+	require.Equal(t, "1.24", val.NumberContext{Precision: 3}.Apply(testDecimal("1.2351")).Decimal().String())
+	require.True(t, val.DefaultNumberContext.Sum(val.Tuple(val.Nan())).IsNaN())
+
+}
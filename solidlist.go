@@ -163,7 +163,7 @@ func (t solidListValue) GetAt(i int) Value {
 
 func (t solidListValue) GetBoolAt(index int) Bool {
 	value := t.GetAt(index)
-	if value != nil {
+	if !IsNull(value) {
 		if value.Kind() == BOOL {
 			return value.(Bool)
 		}
@@ -174,7 +174,7 @@ func (t solidListValue) GetBoolAt(index int) Bool {
 
 func (t solidListValue) GetNumberAt(index int) Number {
 	value := t.GetAt(index)
-	if value != nil {
+	if !IsNull(value) {
 		if value.Kind() == NUMBER {
 			return value.(Number)
 		}
@@ -185,7 +185,7 @@ func (t solidListValue) GetNumberAt(index int) Number {
 
 func (t solidListValue) GetStringAt(index int) String {
 	value := t.GetAt(index)
-	if value != nil {
+	if !IsNull(value) {
 		if value.Kind() == STRING {
 			return value.(String)
 		}
@@ -196,7 +196,7 @@ func (t solidListValue) GetStringAt(index int) String {
 
 func (t solidListValue) GetListAt(index int) List {
 	value := t.GetAt(index)
-	if value != nil {
+	if !IsNull(value) {
 		switch value.Kind() {
 		case LIST:
 			return value.(List)
@@ -209,7 +209,7 @@ func (t solidListValue) GetListAt(index int) List {
 
 func (t solidListValue) GetMapAt(index int) Map {
 	value := t.GetAt(index)
-	if value != nil {
+	if !IsNull(value) {
 		switch value.Kind() {
 		case LIST:
 			return SortedMap(value.(List).Entries(), false)
@@ -220,6 +220,17 @@ func (t solidListValue) GetMapAt(index int) Map {
 	return nil
 }
 
+func (t solidListValue) GetTimeAt(index int) Time {
+	value := t.GetAt(index)
+	if !IsNull(value) {
+		if value.Kind() == TIME {
+			return value.(Time)
+		}
+		return ParseTime(value.String())
+	}
+	return nil
+}
+
 func (t solidListValue) Append(val Value) List {
 	return t.append(len(t), val)
 }
@@ -287,15 +298,11 @@ func (t solidListValue) insertAt(i, n int, val Value) List {
 		dst[0] = val
 		return solidListValue(dst)
 	} else if i+1 == n {
-		if AllowFastAppends {   // fast appends are permitted w/o memory allocation
-			return append(t[:i], val, t[i])
-		} else {
-			dst := make([]Value, n+1)
-			copy(dst, t[:i])
-			dst[n-1] = val
-			dst[n] = t[i]
-			return solidListValue(dst)
-		}
+		dst := make([]Value, n+1)
+		copy(dst, t[:i])
+		dst[n-1] = val
+		dst[n] = t[i]
+		return solidListValue(dst)
 	} else {
 		dst := make([]Value, n+1)
 		copy(dst, t[:i])
@@ -309,9 +316,7 @@ func (t solidListValue) removeAt(i, n int) List {
 	if i == 0 {
 		return t[1:]
 	} else if i+1 == n {
-		return t[:i]
-	} else if AllowFastAppends {
-		return append(t[:i], t[i+1:]...)
+		return t[:i:i]
 	} else {
 		dst := make([]Value, n-1)
 		copy(dst, t[:i])
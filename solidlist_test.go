@@ -142,3 +142,17 @@ func TestSolidListJson(t *testing.T) {
 	testPackUnpack(t, b)
 
 }
+
+func TestSolidListCopyOnWrite(t *testing.T) {
+
+	require.True(t, val.AllowFastAppends)
+
+	a := val.SolidList([]val.Value{val.Long(0), val.Long(1), val.Long(2), val.Long(3)})
+	expected := val.Jsonify(a)
+
+	require.Equal(t, "[0,1,2,5,3]", val.Jsonify(a.InsertAt(3, val.Long(5))))
+	require.Equal(t, "[0,2,3]", val.Jsonify(a.RemoveAt(1)))
+	require.Equal(t, "[0,1,2,5]", val.Jsonify(a.RemoveAt(3).Append(val.Long(5))))
+	require.Equal(t, expected, val.Jsonify(a))
+
+}
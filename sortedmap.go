@@ -22,6 +22,7 @@ import (
 	"bytes"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -155,9 +156,7 @@ func (t sortedMapValue) PrintJSON(out *strings.Builder) {
 		if i != 0 {
 			out.WriteRune(',')
 		}
-		out.WriteRune(jsonQuote)
-		out.WriteString(entry.Key())
-		out.WriteRune(jsonQuote)
+		out.WriteString(strconv.Quote(entry.Key()))
 
 		out.WriteString(": ")
 		value := entry.Value()
@@ -217,7 +216,7 @@ func (t sortedMapValue) Get(key string) (Value, bool) {
 
 func (t sortedMapValue) GetBool(key string) Bool {
 	value, _ := t.Get(key)
-	if value != nil {
+	if !IsNull(value) {
 		if value.Kind() == BOOL {
 			return value.(Bool)
 		}
@@ -228,7 +227,7 @@ func (t sortedMapValue) GetBool(key string) Bool {
 
 func (t sortedMapValue) GetNumber(key string) Number {
 	value, _ := t.Get(key)
-	if value != nil {
+	if !IsNull(value) {
 		if value.Kind() == NUMBER {
 			return value.(Number)
 		}
@@ -239,7 +238,7 @@ func (t sortedMapValue) GetNumber(key string) Number {
 
 func (t sortedMapValue) GetString(key string) String {
 	value, _ := t.Get(key)
-	if value != nil {
+	if !IsNull(value) {
 		if value.Kind() == STRING {
 			return value.(String)
 		}
@@ -250,7 +249,7 @@ func (t sortedMapValue) GetString(key string) String {
 
 func (t sortedMapValue) GetList(key string) List {
 	value, _ := t.Get(key)
-	if value != nil {
+	if !IsNull(value) {
 		switch value.Kind() {
 		case LIST:
 			return value.(List)
@@ -263,7 +262,7 @@ func (t sortedMapValue) GetList(key string) List {
 
 func (t sortedMapValue) GetMap(key string) Map {
 	value, _ := t.Get(key)
-	if value != nil {
+	if !IsNull(value) {
 		switch value.Kind() {
 		case LIST:
 			return SortedMap(value.(List).Entries(), false)
@@ -274,6 +273,17 @@ func (t sortedMapValue) GetMap(key string) Map {
 	return nil
 }
 
+func (t sortedMapValue) GetTime(key string) Time {
+	value, _ := t.Get(key)
+	if !IsNull(value) {
+		if value.Kind() == TIME {
+			return value.(Time)
+		}
+		return ParseTime(value.String())
+	}
+	return nil
+}
+
 func (t sortedMapValue) Insert(key string, value Value) Map {
 	n := len(t)
 	i := sort.Search(n, func(i int) bool {
@@ -341,15 +351,11 @@ func (t sortedMapValue) insertAt(i, n int, entry MapEntry) Map {
 		dst[0] = entry
 		return sortedMapValue(dst)
 	} else if i+1 == n {
-		if AllowFastAppends {  // fast appends are permitted w/o memory allocation
-			return append(t[:i], entry, t[i])
-		} else {
-			dst := make([]MapEntry, n+1)
-			copy(dst, t[:i])
-			dst[n-1] = entry
-			dst[n] = t[i]
-			return sortedMapValue(dst)
-		}
+		dst := make([]MapEntry, n+1)
+		copy(dst, t[:i])
+		dst[n-1] = entry
+		dst[n] = t[i]
+		return sortedMapValue(dst)
 	} else {
 		dst := make([]MapEntry, n+1)
 		copy(dst, t[:i])
@@ -363,9 +369,7 @@ func (t sortedMapValue) removeAt(i, n int) Map {
 	if i == 0 {
 		return t[1:]
 	} else if i+1 == n {
-		return t[:i]
-	} else if AllowFastAppends {
-		return append(t[:i], t[i+1:]...)
+		return t[:i:i]
 	} else {
 		dst := make([]MapEntry, n-1)
 		copy(dst, t[:i])
@@ -461,9 +465,7 @@ func (t sortedMapValue) removeSliceAt(i, cnt, n int) Map {
 	if i == 0 {
 		return t[cnt:]
 	} else if i+cnt == n {
-		return t[:i]
-	} else if AllowFastAppends  {
-		return append(t[:i], t[i+cnt:]...)
+		return t[:i:i]
 	} else {
 		dst := make([]MapEntry, n-cnt)
 		copy(dst, t[:i])
@@ -192,4 +192,19 @@ func TestSortedMapProtocol(t *testing.T) {
 	require.Equal(t,  5, req.Len())
 	require.Equal(t,  "{\"cid\": 555,\"m\": \"vRPC\",\"rid\": 123,\"t\": 1,\"v\": 1}", req.String())
 
-}
\ No newline at end of file
+}
+
+func TestSortedMapCopyOnWrite(t *testing.T) {
+
+	require.True(t, val.AllowFastAppends)
+
+	a := val.EmptyMap().Put("a", val.Long(1)).Put("b", val.Long(2)).Put("c", val.Long(3)).Put("d", val.Long(4))
+	expected := val.Jsonify(a)
+
+	require.Equal(t, 5, a.Insert("d", val.Long(5)).Len())
+	require.Equal(t, 3, a.Remove("b").Len())
+	require.Equal(t, 4, a.Remove("d").Put("e", val.Long(5)).Len())
+	require.Equal(t, 4, a.DeleteAll("d").Put("e", val.Long(5)).Len())
+	require.Equal(t, expected, val.Jsonify(a))
+
+}
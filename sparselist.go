@@ -246,7 +246,7 @@ func (t sparseListValue) GetAt(key int) Value {
 
 func (t sparseListValue) GetBoolAt(index int) Bool {
 	value := t.GetAt(index)
-	if value != nil {
+	if !IsNull(value) {
 		if value.Kind() == BOOL {
 			return value.(Bool)
 		}
@@ -257,7 +257,7 @@ func (t sparseListValue) GetBoolAt(index int) Bool {
 
 func (t sparseListValue) GetNumberAt(index int) Number {
 	value := t.GetAt(index)
-	if value != nil {
+	if !IsNull(value) {
 		if value.Kind() == NUMBER {
 			return value.(Number)
 		}
@@ -268,7 +268,7 @@ func (t sparseListValue) GetNumberAt(index int) Number {
 
 func (t sparseListValue) GetStringAt(index int) String {
 	value := t.GetAt(index)
-	if value != nil {
+	if !IsNull(value) {
 		if value.Kind() == STRING {
 			return value.(String)
 		}
@@ -279,7 +279,7 @@ func (t sparseListValue) GetStringAt(index int) String {
 
 func (t sparseListValue) GetListAt(index int) List {
 	value := t.GetAt(index)
-	if value != nil {
+	if !IsNull(value) {
 		switch value.Kind() {
 		case LIST:
 			return value.(List)
@@ -292,7 +292,7 @@ func (t sparseListValue) GetListAt(index int) List {
 
 func (t sparseListValue) GetMapAt(index int) Map {
 	value := t.GetAt(index)
-	if value != nil {
+	if !IsNull(value) {
 		switch value.Kind() {
 		case LIST:
 			return SortedMap(value.(List).Entries(), false)
@@ -303,6 +303,17 @@ func (t sparseListValue) GetMapAt(index int) Map {
 	return nil
 }
 
+func (t sparseListValue) GetTimeAt(index int) Time {
+	value := t.GetAt(index)
+	if !IsNull(value) {
+		if value.Kind() == TIME {
+			return value.(Time)
+		}
+		return ParseTime(value.String())
+	}
+	return nil
+}
+
 func (t sparseListValue) Append(value Value) List {
 	n := len(t)
 	if n == 0 {
@@ -380,15 +391,11 @@ func (t sparseListValue) insertAt(i, n int, item ListItem) List {
 		dst[0] = item
 		return sparseListValue(dst)
 	} else if i+1 == n {
-		if AllowFastAppends {  // fast appends are permitted w/o memory allocation
-			return append(t[:i], item, t[i])
-		} else {
-			dst := make([]ListItem, n+1)
-			copy(dst, t[:i])
-			dst[n-1] = item
-			dst[n] = t[i]
-			return sparseListValue(dst)
-		}
+		dst := make([]ListItem, n+1)
+		copy(dst, t[:i])
+		dst[n-1] = item
+		dst[n] = t[i]
+		return sparseListValue(dst)
 	} else {
 		dst := make([]ListItem, n+1)
 		copy(dst, t[:i])
@@ -402,9 +409,7 @@ func (t sparseListValue) removeAt(i, n int) List {
 	if i == 0 {
 		return t[1:]
 	} else if i+1 == n {
-		return t[:i]
-	} else if AllowFastAppends {
-		return append(t[:i], t[i+1:]...)
+		return t[:i:i]
 	} else {
 		dst := make([]ListItem, n-1)
 		copy(dst, t[:i])
@@ -500,9 +505,7 @@ func (t sparseListValue) removeSliceAt(i, cnt, n int) List {
 	if i == 0 {
 		return t[cnt:]
 	} else if i+cnt == n {
-		return t[:i]
-	} else if AllowFastAppends  {
-		return append(t[:i], t[i+cnt:]...)
+		return t[:i:i]
 	} else {
 		dst := make([]ListItem, n-cnt)
 		copy(dst, t[:i])
@@ -167,4 +167,19 @@ func TestSparseListInsert(t *testing.T) {
 	require.Equal(t, 0, b.Len())
 
 
-}
\ No newline at end of file
+}
+
+func TestSparseListCopyOnWrite(t *testing.T) {
+
+	require.True(t, val.AllowFastAppends)
+
+	a := val.SparseList([]val.ListItem{val.Item(1, val.Long(1)), val.Item(2, val.Long(2)), val.Item(3, val.Long(3)), val.Item(4, val.Long(4))}, true)
+	expected := val.Jsonify(a)
+
+	require.Equal(t, "{\"1\": 1,\"2\": 2,\"3\": 3,\"4\": 5,\"4\": 4}", val.Jsonify(a.InsertAt(4, val.Long(5))))
+	require.Equal(t, "{\"1\": 1,\"3\": 3,\"4\": 4}", val.Jsonify(a.RemoveAt(2)))
+	require.Equal(t, "{\"1\": 1,\"2\": 2,\"3\": 3,\"4\": 5}", val.Jsonify(a.RemoveAt(4).Append(val.Long(5))))
+	require.Equal(t, "{\"1\": 1,\"2\": 2,\"3\": 3,\"4\": 5}", val.Jsonify(a.DeleteAll(4).Append(val.Long(5))))
+	require.Equal(t, expected, val.Jsonify(a))
+
+}
@@ -21,10 +21,13 @@ package value
 import (
 	"bytes"
 	"github.com/pkg/errors"
+	"math"
+	"math/big"
 	"reflect"
 	"sort"
 	"strconv"
 	"sync"
+	"time"
 )
 
 /**
@@ -37,6 +40,10 @@ func PackStruct(obj interface{}) ([]byte, error) {
 	if obj != nil {
 		if val, ok := obj.(Value); ok {
 			val.Pack(p)
+		} else if gen, ok := obj.(StructPacker); ok {
+			if err := gen.PackValue(p); err != nil {
+				return nil, err
+			}
 		} else if err := reflectPackStruct(p, obj); err != nil {
 			return nil, err
 		}
@@ -46,9 +53,20 @@ func PackStruct(obj interface{}) ([]byte, error) {
 	return buf.Bytes(), p.Error()
 }
 
-func UnpackStruct(buf []byte, obj interface{}, copy bool) error {
+/**
+	Unpacks struct from MessagePack map with tag keys
+
+	Unknown tags are kept in the field with `unknown:"true"` if any, skipped with SkipUnknownTags option, otherwise fail.
+	Absent fields get values from `default:"..."` tags, absent fields with `required:"true"` fail.
+	Uses generated ParseValue instead of reflection if available, see StructParser.
+*/
+
+func UnpackStruct(buf []byte, obj interface{}, copy bool, options... UnpackOption) error {
 	unpacker := MessageUnpacker(buf, copy)
 	parser := MessageParser()
+	if gen, ok := obj.(StructParser); ok {
+		return gen.ParseValue(unpacker, parser, options...)
+	}
 	classPtr := reflect.TypeOf(obj)
 	if classPtr.Kind() != reflect.Ptr {
 		return errors.Errorf("non-pointer instance is not allowed in '%v'", classPtr)
@@ -58,7 +76,7 @@ func UnpackStruct(buf []byte, obj interface{}, copy bool) error {
 	} else {
 		valuePtr := reflect.ValueOf(obj)
 		value := valuePtr.Elem()
-		return ParseStruct(unpacker, parser, value, schema)
+		return ParseStruct(unpacker, parser, value, schema, options...)
 	}
 }
 
@@ -79,6 +97,8 @@ func reflectPackStruct(p *messagePacker, obj interface{}) error {
 type packingField struct {
 	field       *Field
 	fieldValue  reflect.Value
+	tag         int
+	unknown     Value  // value of the unknown tag if field is nil
 }
 
 func doReflectPackStruct(p *messagePacker, value reflect.Value, schema *Schema) error {
@@ -88,8 +108,9 @@ func doReflectPackStruct(p *messagePacker, value reflect.Value, schema *Schema)
 		f := &packingField {
 			field: field,
 			fieldValue: value.Field(field.FieldNum),
+			tag: field.Tag,
 		}
-		if !f.fieldValue.IsNil() {
+		if !isNilField(f.fieldValue) {
 			list = append(list, f)
 			if f.field.Array && f.field.Repeated {
 				cnt += f.fieldValue.Len()
@@ -98,9 +119,37 @@ func doReflectPackStruct(p *messagePacker, value reflect.Value, schema *Schema)
 			}
 		}
 	}
+	if schema.Unknown != nil {
+		if unknown, ok := value.Field(schema.Unknown.FieldNum).Interface().(Map); ok && unknown != nil {
+			for _, entry := range unknown.Entries() {
+				tag, err := strconv.Atoi(entry.Key())
+				if err != nil {
+					return errors.Errorf("invalid tag '%s' in unknown field %s", entry.Key(), schema.Unknown.FieldName)
+				}
+				if _, ok := schema.Fields[tag]; ok {
+					return errors.Errorf("tag %d in unknown field %s is used by the field %s", tag, schema.Unknown.FieldName, schema.Fields[tag].FieldName)
+				}
+				list = append(list, &packingField{tag: tag, unknown: entry.Value()})
+				cnt++
+			}
+			sort.SliceStable(list, func(i, j int) bool {
+				return list[i].tag < list[j].tag
+			})
+		}
+	}
 	p.PackMap(cnt)
 	for _, entry := range list {
 
+		if entry.field == nil {
+			p.PackLong(int64(entry.tag))
+			if entry.unknown != nil {
+				entry.unknown.Pack(p)
+			} else {
+				p.PackNil()
+			}
+			continue
+		}
+
 		if entry.field.Array {
 
 			cnt := entry.fieldValue.Len()
@@ -133,6 +182,16 @@ func doReflectPackValue(p *messagePacker, value reflect.Value, entry *packingFie
 		if err := doReflectPackStruct(p, value.Elem(), entry.field.FieldSchema); err != nil {
 			return errors.Errorf("can not pack field %v, inner struct error %v", value, err)
 		}
+	} else if entry.field.Native {
+		val, err := nativeToValue(value)
+		if err != nil {
+			return errors.Errorf("can not pack field %s, %v", entry.field.FieldName, err)
+		}
+		if val != nil {
+			val.Pack(p)
+		} else {
+			p.PackNil()
+		}
 	} else {
 		fieldObject := value.Interface()
 		if val, ok := fieldObject.(Value); ok {
@@ -144,13 +203,30 @@ func doReflectPackValue(p *messagePacker, value reflect.Value, entry *packingFie
 	return nil
 }
 
+/**
+	Native fields of non-nullable types are always packed, even with zero values
+*/
+
+func isNilField(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
+
 type Field struct {
 	FieldNum       int
 	FieldType      reflect.Type
 	FieldName      string
+	Name           string  // key in field-name keyed maps from `name:"..."`, see ToValue
 	Array          bool
 	Struct         bool
+	Native         bool  // Go type mapped to Value, see isNativeType
 	Repeated       bool
+	Required       bool
+	Default        Value  // value of absent field, nil if no default
 	FieldSchema    *Schema
 	Tag            int
 }
@@ -158,8 +234,12 @@ type Field struct {
 type Schema struct {
 	Fields        map[int]*Field   // tag is the key
 	SortedFields  []*Field
+	Names         map[string]*Field  // fields with name
+	Unknown       *Field  // catch-all Map for unknown tags, nil if not declared
 }
 
+var mapClass = reflect.TypeOf((*Map)(nil)).Elem()
+
 var schemaCache sync.Map
 
 type sortableFields []*Field
@@ -190,13 +270,34 @@ func reflectSchema(classPtr reflect.Type) (*Schema, error) {
 func doReflectSchema(classPtr reflect.Type) (*Schema, error) {
 	fields := make(map[int]*Field)
 	var sortedFields []*Field
+	var unknownField *Field
 	class := classPtr.Elem()
 	for j := 0; j < class.NumField(); j++ {
 		field := class.Field(j)
+		if unk, ok := field.Tag.Lookup("unknown"); ok {
+			if isUnknown, _ := strconv.ParseBool(unk); isUnknown {
+				if field.Type != mapClass {
+					return nil, errors.Errorf("unknown field '%s' in class '%v' must be value.Map, actual '%v'", field.Name, classPtr, field.Type)
+				}
+				if unknownField != nil {
+					return nil, errors.Errorf("second unknown field '%s' in class '%v'", field.Name, classPtr)
+				}
+				unknownField = &Field{
+					FieldNum:   j,
+					FieldType:  field.Type,
+					FieldName:  field.Name,
+				}
+				continue
+			}
+		}
 		repeated := false
 		if rep, ok := field.Tag.Lookup("repeated"); ok {
 			repeated, _ = strconv.ParseBool(rep)
 		}
+		required := false
+		if req, ok := field.Tag.Lookup("required"); ok {
+			required, _ = strconv.ParseBool(req)
+		}
 		tagStr, ok := field.Tag.Lookup("tag")
 		if !ok {
 			return nil, errors.Errorf("no tag in field '%s' in class '%v'", field.Name, classPtr)
@@ -207,11 +308,24 @@ func doReflectSchema(classPtr reflect.Type) (*Schema, error) {
 		}
 		array := false
 		fieldType := field.Type
-		if field.Type.Kind() == reflect.Slice || field.Type.Kind() == reflect.Array {
+		if (field.Type.Kind() == reflect.Slice || field.Type.Kind() == reflect.Array) && !isNativeType(field.Type) {
 			fieldType = fieldType.Elem()
 			array = true
 		}
-		if fieldType.Implements(ValueClass) {
+		if !fieldType.Implements(ValueClass) && isNativeType(fieldType) {
+			f := &Field{
+				FieldNum:   j,
+				FieldType:  field.Type,
+				FieldName:  field.Name,
+				Array:      array,
+				Native:     true,
+				Repeated:   repeated,
+				Required:   required,
+				Tag:        tag,
+			}
+			fields[tag] = f
+			sortedFields = append(sortedFields, f)
+		} else if fieldType.Implements(ValueClass) {
 			f := &Field{
 				FieldNum:   j,
 				FieldType:  field.Type,
@@ -219,6 +333,7 @@ func doReflectSchema(classPtr reflect.Type) (*Schema, error) {
 				Array:      array,
 				Struct:     false,
 				Repeated:   repeated,
+				Required:   required,
 				Tag:        tag,
 			}
 			fields[tag] = f
@@ -235,6 +350,7 @@ func doReflectSchema(classPtr reflect.Type) (*Schema, error) {
 				Array:   array,
 				Struct:   true,
 				Repeated: repeated,
+				Required: required,
 				FieldSchema: fieldSchema,
 				Tag: tag,
 			}
@@ -242,33 +358,110 @@ func doReflectSchema(classPtr reflect.Type) (*Schema, error) {
 			sortedFields = append(sortedFields, f)
 		}
 	}
+	names := make(map[string]*Field)
+	for _, f := range sortedFields {
+		if name, ok := class.Field(f.FieldNum).Tag.Lookup("name"); ok {
+			if _, err := strconv.Atoi(name); err == nil {
+				return nil, errors.Errorf("name '%s' of field '%s' in class '%v' is a number, it would clash with tag keys", name, f.FieldName, classPtr)
+			}
+			if other, ok := names[name]; ok {
+				return nil, errors.Errorf("name '%s' of field '%s' is used by the field '%s' in class '%v'", name, f.FieldName, other.FieldName, classPtr)
+			}
+			f.Name = name
+			names[name] = f
+		}
+		if def, ok := class.Field(f.FieldNum).Tag.Lookup("default"); ok {
+			val, err := parseDefault(f, def)
+			if err != nil {
+				return nil, errors.Errorf("invalid default '%s' of field '%s' in class '%v', %v", def, f.FieldName, classPtr, err)
+			}
+			f.Default = val
+		}
+	}
 	sort.Sort(sortableFields(sortedFields))
 	return &Schema {
 		Fields: fields,
 		SortedFields: sortedFields,
+		Names: names,
+		Unknown: unknownField,
 	}, nil
 }
 
+var stringClass = reflect.TypeOf((*String)(nil)).Elem()
+var timeInterfaceClass = reflect.TypeOf((*Time)(nil)).Elem()
 
-func ParseStruct(unpacker Unpacker, parser Parser, value reflect.Value, schema *Schema) error {
+/**
+	Parses default tag of the field and checks that it can be set to the field
+
+	String fields take the text as is, Time fields take RFC 3339, other fields take JSON.
+*/
+
+func parseDefault(f *Field, def string) (Value, error) {
+	if f.Array || f.Struct {
+		return nil, errors.New("default is supported only for single value fields")
+	}
+	var val Value
+	switch {
+	case f.FieldType == stringClass || f.FieldType.Kind() == reflect.String:
+		val = Utf8(def)
+	case f.FieldType.Kind() == reflect.Slice:
+		val = Raw([]byte(def), true)
+	case f.FieldType == timeInterfaceClass || f.FieldType == timeClass:
+		if val = ParseTime(def); val == nil {
+			return nil, errors.New("expected RFC 3339 time")
+		}
+	default:
+		var err error
+		if val, err = ParseJSON([]byte(def)); err != nil {
+			return nil, err
+		}
+	}
+	check := reflect.New(f.FieldType).Elem()
+	if err := setFieldValue(check, f.FieldType, val); err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+
+/**
+	Parses struct from MessagePack map with tag keys, see UnpackStruct
+*/
+
+func ParseStruct(unpacker Unpacker, parser Parser, value reflect.Value, schema *Schema, options... UnpackOption) error {
+	return doParseStruct(unpacker, parser, value, schema, newParseContext(options), value.Type().Name())
+}
+
+/**
+	Path is the name of the struct type followed by the names of fields, errors name the path of the struct
+*/
+
+func doParseStruct(unpacker Unpacker, parser Parser, value reflect.Value, schema *Schema, ctx *parseContext, path string) error {
 	format, header := unpacker.Next()
 	if format != MapHeader {
-		return errors.Errorf("expected MapHeader for struct, but got %v", format)
+		return errors.Errorf("expected MapHeader for struct '%s', but got %v", path, format)
 	}
 	cnt := parser.ParseMap(header)
 	if parser.Error() != nil {
 		return parser.Error()
 	}
+	seen := make(map[int]bool)
+	var unknown *MapBuilder
+	if schema.Unknown != nil {
+		unknown = NewMapBuilder(0, KeepAll)
+	}
 	for i := 0; i < cnt; i++ {
-		key, err := doParse(unpacker, parser)
+		key, err := doParse(unpacker, parser, ctx)
 		if err != nil {
-			return errors.Errorf("fail to parse key on position %d, %v", i, err)
+			return errors.Errorf("fail to parse key on position %d in '%s', %v", i, path, err)
 		}
-		if key.Kind() != NUMBER {
-			return errors.Errorf("expected int key, but got %s on position %d", key.Kind().String(), i)
+		if IsNull(key) || key.Kind() != NUMBER {
+			return errors.Errorf("expected int key, but got %v on position %d in '%s'", key, i, path)
 		}
 		tag := int(key.(Number).Long())
 		if field, ok := schema.Fields[tag]; ok {
+			seen[tag] = true
+			fieldPath := path + "." + field.FieldName
 			fieldValue := value.Field(field.FieldNum)
 			if field.Array {
 
@@ -290,18 +483,18 @@ func ParseStruct(unpacker Unpacker, parser Parser, value reflect.Value, schema *
 						if field.Struct {
 							structValue := reflect.New(elemValue.Type().Elem())
 							elemValue.Set(structValue)
-							err := ParseStruct(unpacker, parser, elemValue.Elem(), field.FieldSchema)
+							err := doParseStruct(unpacker, parser, elemValue.Elem(), field.FieldSchema, ctx, fieldPath+"["+strconv.Itoa(j)+"]")
 							if err != nil {
 								return errors.Errorf("fail to set struct value %v", err)
 							}
 						} else {
-							val, err := doParse(unpacker, parser)
+							val, err := doParse(unpacker, parser, ctx)
 							if err != nil {
 								return errors.Errorf("fail to parse value %v", err)
 							}
 							err = setFieldValue(elemValue, field.FieldType.Elem(), val)
 							if err != nil {
-								return errors.Errorf("fail to set value %v", err)
+								return errors.Errorf("fail to set value of field '%s', %v", fieldPath, err)
 							}
 						}
 					}
@@ -320,38 +513,76 @@ func ParseStruct(unpacker Unpacker, parser Parser, value reflect.Value, schema *
 						structValue := reflect.New(ptrType.Elem())
 						elemValue.Set(structValue)
 						sliceValue = reflect.Append(sliceValue, elemValue)
-						err := ParseStruct(unpacker, parser, elemValue.Elem(), field.FieldSchema)
+						err := doParseStruct(unpacker, parser, elemValue.Elem(), field.FieldSchema, ctx, fieldPath+"["+strconv.Itoa(sliceValue.Len()-1)+"]")
 						if err != nil {
 							return errors.Errorf("fail to set struct value %v", err)
 						}
 					} else {
 						elemValue = reflect.New(field.FieldType.Elem()).Elem()
-						sliceValue = reflect.Append(sliceValue, elemValue)
-						val, err := doParse(unpacker, parser)
+						val, err := doParse(unpacker, parser, ctx)
 						if err != nil {
 							return errors.Errorf("fail to parse value %v", err)
 						}
 						err = setFieldValue(elemValue, field.FieldType.Elem(), val)
 						if err != nil {
-							return errors.Errorf("fail to set value %v", err)
+							return errors.Errorf("fail to set value of field '%s', %v", fieldPath, err)
 						}
+						sliceValue = reflect.Append(sliceValue, elemValue)
 					}
 					fieldValue.Set(sliceValue)
 				}
 			} else {
-				err = parseFieldValue(unpacker, parser, field, fieldValue)
+				err = parseFieldValue(unpacker, parser, field, fieldValue, ctx, fieldPath)
 				if err != nil {
-					return errors.Errorf("parse field on position %d, %v", i, err)
+					return errors.Errorf("parse field '%s' on position %d, %v", fieldPath, i, err)
 				}
 			}
+		} else if unknown != nil || ctx.skipUnknown {
+			val, err := doParse(unpacker, parser, ctx)
+			if err != nil {
+				return errors.Errorf("fail to parse unknown tag %d in '%s', %v", tag, path, err)
+			}
+			if unknown != nil {
+				unknown.Put(strconv.Itoa(tag), val)
+			}
+		} else {
+			return errors.Errorf("unknown tag %d on position %d in '%s'", tag, i, path)
+		}
+	}
+	if unknown != nil {
+		unknownValue := value.Field(schema.Unknown.FieldNum)
+		if unknown.Len() > 0 {
+			unknownValue.Set(reflect.ValueOf(unknown.Freeze()))
 		} else {
-			return errors.Errorf("unknown tag %d on position %d", tag, i)
+			unknownValue.Set(reflect.Zero(unknownValue.Type()))
+		}
+	}
+	return setAbsentFields(value, schema, seen, path)
+}
+
+/**
+	Checks required fields and sets defaults to the fields with tags not seen in the struct
+*/
+
+func setAbsentFields(value reflect.Value, schema *Schema, seen map[int]bool, path string) error {
+	for _, field := range schema.SortedFields {
+		if seen[field.Tag] {
+			continue
+		}
+		fieldPath := path + "." + field.FieldName
+		if field.Required {
+			return errors.Errorf("required field '%s' with tag %d is missing", fieldPath, field.Tag)
+		}
+		if field.Default != nil {
+			if err := setFieldValue(value.Field(field.FieldNum), field.FieldType, field.Default); err != nil {
+				return errors.Errorf("fail to set default of field '%s', %v", fieldPath, err)
+			}
 		}
 	}
 	return nil
 }
 
-func parseFieldValue(unpacker Unpacker, parser Parser, field *Field, fieldValue reflect.Value) error {
+func parseFieldValue(unpacker Unpacker, parser Parser, field *Field, fieldValue reflect.Value, ctx *parseContext, path string) error {
 	if field.Struct {
 		if fieldValue.IsNil() {
 			if fieldValue.CanSet() {
@@ -360,12 +591,12 @@ func parseFieldValue(unpacker Unpacker, parser Parser, field *Field, fieldValue
 				return errors.Errorf("can not set empty struct value to field %v", field.FieldName)
 			}
 		}
-		err := ParseStruct(unpacker, parser, fieldValue.Elem(), field.FieldSchema)
+		err := doParseStruct(unpacker, parser, fieldValue.Elem(), field.FieldSchema, ctx, path)
 		if err != nil {
 			return errors.Errorf("fail to set struct value %v", err)
 		}
 	} else {
-		val, err := doParse(unpacker, parser)
+		val, err := doParse(unpacker, parser, ctx)
 		if err != nil {
 			return errors.Errorf("fail to parse value %v", err)
 		}
@@ -379,7 +610,14 @@ func parseFieldValue(unpacker Unpacker, parser Parser, field *Field, fieldValue
 
 
 func setFieldValue(fieldValue reflect.Value, fieldType reflect.Type, val Value) error {
+	if fieldValue.CanSet() && !fieldType.Implements(ValueClass) {
+		return setNativeValue(fieldValue, val)
+	}
 	if fieldValue.CanSet() {
+		if IsNull(val) {
+			fieldValue.Set(reflect.Zero(fieldType))
+			return nil
+		}
 		if !val.Class().AssignableTo(fieldType) {
 			return errors.Errorf("expected value type %v, actual %v", fieldType, val.Class())
 		}
@@ -392,3 +630,342 @@ func setFieldValue(fieldValue reflect.Value, fieldType reflect.Type, val Value)
 }
 
 
+
+var timeClass = reflect.TypeOf((*time.Time)(nil)).Elem()
+
+/**
+	Go types mapped to Value encodings
+
+	bool              BOOL
+	int, int8..int64  NUMBER as LONG
+	uint..uint64      NUMBER as LONG or ULONG
+	float32           NUMBER as FLOAT
+	float64           NUMBER as DOUBLE
+	string            STRING as UTF8
+	[]byte            STRING as RAW
+	time.Time         TIME
+	map[string]T      MAP, where T is a native type or implements Value
+*/
+
+func isNativeType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.Uint8
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String && (t.Elem().Implements(ValueClass) || isNativeType(t.Elem()))
+	case reflect.Struct:
+		return t == timeClass
+	default:
+		return false
+	}
+}
+
+func nativeToValue(v reflect.Value) (Value, error) {
+	if v.Type().Implements(ValueClass) {
+		if v.IsNil() {
+			return nil, nil
+		}
+		return v.Interface().(Value), nil
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return Boolean(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Long(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Unsigned(v.Uint()), nil
+	case reflect.Float32:
+		return Float(float32(v.Float())), nil
+	case reflect.Float64:
+		return Double(v.Float()), nil
+	case reflect.String:
+		return Utf8(v.String()), nil
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return Raw(v.Bytes(), true), nil
+	case reflect.Map:
+		if v.IsNil() {
+			return nil, nil
+		}
+		entries := make([]MapEntry, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			elem, err := nativeToValue(iter.Value())
+			if err != nil {
+				return nil, errors.Errorf("map key '%s', %v", iter.Key().String(), err)
+			}
+			entries = append(entries, Entry(iter.Key().String(), elem))
+		}
+		return SortedMap(entries, false), nil
+	case reflect.Struct:
+		if t, ok := v.Interface().(time.Time); ok {
+			return Timestamp(t), nil
+		}
+	}
+	return nil, errors.Errorf("unsupported native type %v", v.Type())
+}
+
+/**
+	Sets Value to the native field, nil and Null set zero value, numbers are checked for overflow
+*/
+
+func setNativeValue(fieldValue reflect.Value, val Value) error {
+	fieldType := fieldValue.Type()
+	if fieldType.Implements(ValueClass) {
+		if IsNull(val) {
+			fieldValue.Set(reflect.Zero(fieldType))
+			return nil
+		}
+		if !val.Class().AssignableTo(fieldType) {
+			return errors.Errorf("expected value type %v, actual %v", fieldType, val.Class())
+		}
+		fieldValue.Set(reflect.ValueOf(val))
+		return nil
+	}
+	if IsNull(val) {
+		fieldValue.Set(reflect.Zero(fieldType))
+		return nil
+	}
+	switch fieldType.Kind() {
+	case reflect.Bool:
+		b, err := NativeBool(val)
+		if err != nil {
+			return err
+		}
+		fieldValue.SetBool(b)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := NativeInt(val, nativeBits(fieldType))
+		if err != nil {
+			return err
+		}
+		fieldValue.SetInt(i)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := NativeUint(val, nativeBits(fieldType))
+		if err != nil {
+			return err
+		}
+		fieldValue.SetUint(u)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, err := NativeFloat(val, fieldType.Bits())
+		if err != nil {
+			return err
+		}
+		fieldValue.SetFloat(f)
+		return nil
+	case reflect.String:
+		str, err := NativeString(val)
+		if err != nil {
+			return err
+		}
+		fieldValue.SetString(str)
+		return nil
+	case reflect.Slice:
+		b, err := NativeBytes(val)
+		if err != nil {
+			return err
+		}
+		fieldValue.SetBytes(b)
+		return nil
+	case reflect.Map:
+		if val.Kind() != MAP {
+			return errors.Errorf("expected MAP for %v, actual %v", fieldType, val.Kind())
+		}
+		m := reflect.MakeMapWithSize(fieldType, val.(Map).Len())
+		for _, entry := range val.(Map).Entries() {
+			elem := reflect.New(fieldType.Elem()).Elem()
+			if err := setNativeValue(elem, entry.Value()); err != nil {
+				return errors.Errorf("map key '%s', %v", entry.Key(), err)
+			}
+			m.SetMapIndex(reflect.ValueOf(entry.Key()).Convert(fieldType.Key()), elem)
+		}
+		fieldValue.Set(m)
+		return nil
+	case reflect.Struct:
+		t, err := NativeTime(val)
+		if err != nil {
+			return err
+		}
+		fieldValue.Set(reflect.ValueOf(t))
+		return nil
+	}
+	return errors.Errorf("unsupported native type %v", fieldType)
+}
+
+/**
+	Bits of int and uint are 0, see NativeInt
+*/
+
+func nativeBits(t reflect.Type) int {
+	if t.Kind() == reflect.Int || t.Kind() == reflect.Uint {
+		return 0
+	}
+	return t.Bits()
+}
+
+func nativeTypeName(prefix string, bits int) string {
+	if bits == 0 {
+		return prefix
+	}
+	return prefix + strconv.Itoa(bits)
+}
+
+/**
+	Converts Value to native bool, nil and Null give false
+*/
+
+func NativeBool(val Value) (bool, error) {
+	if IsNull(val) {
+		return false, nil
+	}
+	if val.Kind() != BOOL {
+		return false, errors.Errorf("expected BOOL for bool, actual %v", val.Kind())
+	}
+	return val.(Bool).Boolean(), nil
+}
+
+/**
+	Converts Value to native signed integer of the bit size, 0 bits stands for int
+
+	Nil and Null give 0, fractions and overflows are errors.
+*/
+
+func NativeInt(val Value, bits int) (int64, error) {
+	if IsNull(val) {
+		return 0, nil
+	}
+	typeName := nativeTypeName("int", bits)
+	i, err := nativeInteger(val, typeName)
+	if err != nil {
+		return 0, err
+	}
+	if bits == 0 {
+		bits = strconv.IntSize
+	}
+	if !i.IsInt64() || (bits < 64 && (i.Int64() < -1<<uint(bits-1) || i.Int64() > 1<<uint(bits-1)-1)) {
+		return 0, errors.Errorf("number %v overflows %s", i, typeName)
+	}
+	return i.Int64(), nil
+}
+
+/**
+	Converts Value to native unsigned integer of the bit size, 0 bits stands for uint
+
+	Nil and Null give 0, fractions and overflows are errors.
+*/
+
+func NativeUint(val Value, bits int) (uint64, error) {
+	if IsNull(val) {
+		return 0, nil
+	}
+	typeName := nativeTypeName("uint", bits)
+	i, err := nativeInteger(val, typeName)
+	if err != nil {
+		return 0, err
+	}
+	if bits == 0 {
+		bits = strconv.IntSize
+	}
+	if !i.IsUint64() || (bits < 64 && i.Uint64() > 1<<uint(bits)-1) {
+		return 0, errors.Errorf("number %v overflows %s", i, typeName)
+	}
+	return i.Uint64(), nil
+}
+
+/**
+	Converts Value to native float of the bit size 32 or 64, nil and Null give 0
+*/
+
+func NativeFloat(val Value, bits int) (float64, error) {
+	if IsNull(val) {
+		return 0, nil
+	}
+	typeName := nativeTypeName("float", bits)
+	if val.Kind() != NUMBER {
+		return 0, errors.Errorf("expected NUMBER for %s, actual %v", typeName, val.Kind())
+	}
+	f := val.(Number).Double()
+	if bits == 32 && !math.IsInf(f, 0) && !math.IsNaN(f) && math.Abs(f) > math.MaxFloat32 {
+		return 0, errors.Errorf("number %v overflows %s", f, typeName)
+	}
+	return f, nil
+}
+
+/**
+	Converts Value to native string, raw strings are taken as is, nil and Null give empty string
+*/
+
+func NativeString(val Value) (string, error) {
+	if IsNull(val) {
+		return "", nil
+	}
+	if val.Kind() != STRING {
+		return "", errors.Errorf("expected STRING for string, actual %v", val.Kind())
+	}
+	if s := val.(String); s.Type() == UTF8 {
+		return s.Utf8(), nil
+	} else {
+		return string(s.Raw()), nil
+	}
+}
+
+/**
+	Converts Value to the copy of bytes, nil and Null give nil
+*/
+
+func NativeBytes(val Value) ([]byte, error) {
+	if IsNull(val) {
+		return nil, nil
+	}
+	if val.Kind() != STRING {
+		return nil, errors.Errorf("expected STRING for []byte, actual %v", val.Kind())
+	}
+	return append([]byte(nil), val.(String).Raw()...), nil
+}
+
+/**
+	Converts Value to native time, nil and Null give zero time
+*/
+
+func NativeTime(val Value) (time.Time, error) {
+	if IsNull(val) {
+		return time.Time{}, nil
+	}
+	if val.Kind() != TIME {
+		return time.Time{}, errors.Errorf("expected TIME for time.Time, actual %v", val.Kind())
+	}
+	return val.(Time).Time(), nil
+}
+
+/**
+	Gets exact integer value of the number, fractional numbers are not converted
+*/
+
+func nativeInteger(val Value, typeName string) (*big.Int, error) {
+	if val.Kind() != NUMBER {
+		return nil, errors.Errorf("expected NUMBER for %s, actual %v", typeName, val.Kind())
+	}
+	n := val.(Number)
+	switch n.Type() {
+	case LONG:
+		return big.NewInt(n.Long()), nil
+	case ULONG, BIGINT:
+		return n.BigInt(), nil
+	}
+	if !isFiniteNumber(n) {
+		return nil, errors.Errorf("number %v can not be converted to %s", n, typeName)
+	}
+	r := numberRat(n)
+	if !r.IsInt() {
+		return nil, errors.Errorf("number %v has fraction for %s", n, typeName)
+	}
+	return r.Num(), nil
+}
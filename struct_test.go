@@ -22,7 +22,9 @@ import (
 	"encoding/hex"
 	"arpabet.pkg.is/value"
 	"github.com/stretchr/testify/require"
+	"math"
 	"testing"
+	"time"
 )
 
 /**
@@ -188,3 +190,309 @@ func TestArrayStruct(t *testing.T) {
 	require.Nil(t, err)
 
 }
+
+type NativeExample struct {
+
+	BoolField       bool                   `tag:"1"`
+	NumberField     int64                  `tag:"2"`
+	StringField     string                 `tag:"3"`
+	BytesField      []byte                 `tag:"4"`
+	MapField        map[string]int         `tag:"5"`
+	TimeField       time.Time              `tag:"6"`
+	DoubleField     float64                `tag:"7"`
+	FloatField      float32                `tag:"8"`
+	UintField       uint64                 `tag:"9"`
+	ListField       []string               `tag:"10"`
+	RepField        []int32                `tag:"11" repeated:"true"`
+	ValueMapField   map[string]value.Value `tag:"12"`
+
+}
+
+type ValueExample struct {
+
+	BoolField       value.Bool      `tag:"1"`
+	NumberField     value.Number    `tag:"2"`
+	StringField     value.String    `tag:"3"`
+	BytesField      value.String    `tag:"4"`
+	MapField        value.Map       `tag:"5"`
+	TimeField       value.Time      `tag:"6"`
+	DoubleField     value.Number    `tag:"7"`
+	FloatField      value.Number    `tag:"8"`
+	UintField       value.Number    `tag:"9"`
+	ListField       []value.String  `tag:"10"`
+	RepField        []value.Number  `tag:"11" repeated:"true"`
+	ValueMapField   value.Map       `tag:"12"`
+
+}
+
+func TestNativeStruct(t *testing.T) {
+
+	now := time.Unix(1600000000, 123).UTC()
+
+	s := NativeExample{
+		BoolField: true,
+		NumberField: -123,
+		StringField: "test",
+		BytesField: []byte{1, 2, 3},
+		MapField: map[string]int{"b": 2, "a": 1},
+		TimeField: now,
+		DoubleField: 1.5,
+		FloatField: 2.5,
+		UintField: math.MaxUint64,
+		ListField: []string{"x", "y"},
+		RepField: []int32{7, 8},
+		ValueMapField: map[string]value.Value{"v": value.Utf8("v")},
+	}
+
+	v := ValueExample{
+		BoolField: value.True,
+		NumberField: value.Long(-123),
+		StringField: value.Utf8("test"),
+		BytesField: value.Raw([]byte{1, 2, 3}, false),
+		MapField: value.EmptyMap().Put("a", value.Long(1)).Put("b", value.Long(2)),
+		TimeField: value.Timestamp(now),
+		DoubleField: value.Double(1.5),
+		FloatField: value.Float(2.5),
+		UintField: value.ULong(math.MaxUint64),
+		ListField: []value.String{value.Utf8("x"), value.Utf8("y")},
+		RepField: []value.Number{value.Long(7), value.Long(8)},
+		ValueMapField: value.EmptyMap().Put("v", value.Utf8("v")),
+	}
+
+	blob, err := value.PackStruct(&s)
+	require.Nil(t, err)
+
+	expected, err := value.PackStruct(&v)
+	require.Nil(t, err)
+	require.Equal(t, hex.EncodeToString(expected), hex.EncodeToString(blob))
+
+	var d NativeExample
+	err = value.UnpackStruct(blob, &d, false)
+	require.Nil(t, err)
+	require.Equal(t, s, d)
+
+	var e NativeExample
+	blob, err = value.PackStruct(&e)
+	require.Nil(t, err)
+
+	var d2 NativeExample
+	err = value.UnpackStruct(blob, &d2, false)
+	require.Nil(t, err)
+	require.Equal(t, e.NumberField, d2.NumberField)
+	require.True(t, e.TimeField.Equal(d2.TimeField))
+
+}
+
+type NarrowExample struct {
+
+	Int8Field       int8     `tag:"1"`
+	Uint16Field     uint16   `tag:"2"`
+	FloatField      float32  `tag:"3"`
+	StringField     string   `tag:"4"`
+
+}
+
+type WideExample struct {
+
+	Int8Field       value.Number  `tag:"1"`
+	Uint16Field     value.Number  `tag:"2"`
+	FloatField      value.Number  `tag:"3"`
+	StringField     value.Value   `tag:"4"`
+
+}
+
+func TestNativeStructConversion(t *testing.T) {
+
+	unpack := func(w WideExample) (NarrowExample, error) {
+		blob, err := value.PackStruct(&w)
+		require.Nil(t, err)
+		var n NarrowExample
+		return n, value.UnpackStruct(blob, &n, false)
+	}
+
+	n, err := unpack(WideExample{
+		Int8Field: value.Double(-128),
+		Uint16Field: value.ParseNumber("65535"),
+		FloatField: value.Double(0.5),
+		StringField: value.Raw([]byte("raw"), false),
+	})
+	require.Nil(t, err)
+	require.Equal(t, NarrowExample{-128, 65535, 0.5, "raw"}, n)
+
+	_, err = unpack(WideExample{Int8Field: value.Long(128)})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "overflows int8")
+
+	_, err = unpack(WideExample{Uint16Field: value.Long(-1)})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "overflows uint16")
+
+	_, err = unpack(WideExample{Int8Field: value.Double(1.5)})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "fraction")
+
+	_, err = unpack(WideExample{FloatField: value.Double(1e300)})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "overflows float32")
+
+	_, err = unpack(WideExample{StringField: value.Long(1)})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "expected STRING")
+
+}
+
+type OldExample struct {
+
+	Name       string        `tag:"1"`
+	Count      int           `tag:"2" default:"7"`
+	Label      value.String  `tag:"3" default:"none"`
+	Unknown    value.Map     `unknown:"true"`
+
+}
+
+type NewExample struct {
+
+	Name       string        `tag:"1"`
+	Count      int           `tag:"2"`
+	Label      value.String  `tag:"3"`
+	Extra      value.Value   `tag:"4"`
+	Inner      *Inner        `tag:"5"`
+
+}
+
+type StrictExample struct {
+
+	Name       string        `tag:"1" required:"true"`
+	Flag       bool          `tag:"9"`
+
+}
+
+type StrictHolder struct {
+
+	Strict     *StrictExample  `tag:"1"`
+
+}
+
+type LooseExample struct {
+
+	Flag       bool          `tag:"9"`
+
+}
+
+type LooseHolder struct {
+
+	Loose      *LooseExample   `tag:"1"`
+
+}
+
+func TestStructCompatibility(t *testing.T) {
+
+	n := NewExample{
+		Name: "name",
+		Count: 3,
+		Label: value.Utf8("label"),
+		Extra: value.Long(123),
+		Inner: &Inner{value.Utf8("inner")},
+	}
+	blob, err := value.PackStruct(&n)
+	require.Nil(t, err)
+
+	// unknown tags are kept in the catch-all field and survive re-packing
+	var o OldExample
+	err = value.UnpackStruct(blob, &o, false)
+	require.Nil(t, err)
+	require.Equal(t, "name", o.Name)
+	require.Equal(t, 3, o.Count)
+	require.Equal(t, 2, o.Unknown.Len())
+	require.True(t, value.Long(123).Equal(o.Unknown.GetNumber("4")))
+
+	repacked, err := value.PackStruct(&o)
+	require.Nil(t, err)
+	require.Equal(t, hex.EncodeToString(blob), hex.EncodeToString(repacked))
+
+	var n2 NewExample
+	err = value.UnpackStruct(repacked, &n2, false)
+	require.Nil(t, err)
+	require.Equal(t, "inner", n2.Inner.String.String())
+
+	// unknown tags fail without the catch-all field, unless skipped
+	var s StrictExample
+	err = value.UnpackStruct(blob, &s, false)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "unknown tag 2")
+	require.Contains(t, err.Error(), "StrictExample")
+
+	err = value.UnpackStruct(blob, &s, false, value.SkipUnknownTags())
+	require.Nil(t, err)
+	require.Equal(t, "name", s.Name)
+
+	// defaults applied to absent fields
+	blob, err = value.PackStruct(&StrictExample{Name: "other"})
+	require.Nil(t, err)
+	o = OldExample{}
+	err = value.UnpackStruct(blob, &o, false)
+	require.Nil(t, err)
+	require.Equal(t, 7, o.Count)
+	require.Equal(t, "none", o.Label.String())
+	require.Equal(t, []string{"9"}, o.Unknown.Keys())
+
+	blob, err = value.PackStruct(&OldExample{Name: "other", Count: 1})
+	require.Nil(t, err)
+	o = OldExample{}
+	err = value.UnpackStruct(blob, &o, false)
+	require.Nil(t, err)
+	require.Nil(t, o.Unknown)
+
+	// required field error names the path
+	blob, err = value.PackStruct(&LooseHolder{&LooseExample{Flag: true}})
+	require.Nil(t, err)
+	var h StrictHolder
+	err = value.UnpackStruct(blob, &h, false)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "StrictHolder.Strict.Name")
+
+}
+
+type BadDefaultExample struct {
+
+	Count      int8   `tag:"1" default:"1000"`
+
+}
+
+func TestStructBadDefault(t *testing.T) {
+
+	_, err := value.PackStruct(&BadDefaultExample{})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "Count")
+
+}
+
+type NullFieldExample struct {
+
+	Any        value.Value    `tag:"1"`
+	Str        value.String   `tag:"2"`
+
+}
+
+func TestNullStructField(t *testing.T) {
+
+	blob, err := value.PackStruct(&NullFieldExample{Any: value.Null()})
+	require.Nil(t, err)
+	require.Equal(t, "8101c0", hex.EncodeToString(blob))
+
+	n := NullFieldExample{Any: value.Long(1), Str: value.Utf8("str")}
+	err = value.UnpackStruct(blob, &n, false)
+	require.Nil(t, err)
+	require.Nil(t, n.Any)
+	require.Equal(t, "str", n.Str.String())
+
+	// raw nil payload {2: nil}
+	err = value.UnpackStruct([]byte{0x81, 0x02, 0xc0}, &n, false)
+	require.Nil(t, err)
+	require.Nil(t, n.Str)
+
+	err = value.UnpackStruct([]byte{0x81, 0x02, 0xc0}, &n, false, value.WithNull())
+	require.Nil(t, err)
+	require.Nil(t, n.Str)
+
+}
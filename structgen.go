@@ -0,0 +1,113 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value
+
+import (
+	"github.com/pkg/errors"
+)
+
+/**
+	Struct with generated reflection-free packing, see cmd/valuegen
+
+	PackStruct uses PackValue instead of reflection when the pointer to struct implements it,
+	the generated code produces the same bytes as reflection.
+
+	@author Alex Shvid
+*/
+
+type StructPacker interface {
+
+	PackValue(p Packer) error
+
+}
+
+/**
+	Struct with generated reflection-free parsing, see cmd/valuegen
+
+	UnpackStruct uses ParseValue instead of reflection when the pointer to struct implements it.
+*/
+
+type StructParser interface {
+
+	ParseValue(unpacker Unpacker, parser Parser, options... UnpackOption) error
+
+}
+
+/**
+	Parses the map header of the struct and returns the number of entries, used by the generated code
+*/
+
+func ParseStructHeader(unpacker Unpacker, parser Parser, path string) (int, error) {
+	format, header := unpacker.Next()
+	if format != MapHeader {
+		return 0, errors.Errorf("expected MapHeader for struct '%s', but got %v", path, format)
+	}
+	cnt := parser.ParseMap(header)
+	return cnt, parser.Error()
+}
+
+/**
+	Parses the number key of the struct entry on position i, used by the generated code
+*/
+
+func ParseStructTag(unpacker Unpacker, parser Parser, path string, i int, options... UnpackOption) (int, error) {
+	format, header := unpacker.Next()
+	switch format {
+	case LongToken:
+		return int(parser.ParseLong(header)), parser.Error()
+	case ULongToken:
+		return int(parser.ParseULong(header)), parser.Error()
+	}
+	key, err := doParseToken(format, header, unpacker, parser, newParseContext(options))
+	if err != nil {
+		return 0, errors.Errorf("fail to parse key on position %d in '%s', %v", i, path, err)
+	}
+	if IsNull(key) || key.Kind() != NUMBER {
+		return 0, errors.Errorf("expected int key, but got %v on position %d in '%s'", key, i, path)
+	}
+	return int(key.(Number).Long()), nil
+}
+
+/**
+	Parses the list header of the array field, used by the generated code
+*/
+
+func ParseArrayHeader(unpacker Unpacker, parser Parser) (int, error) {
+	format, header := unpacker.Next()
+	if format != ListHeader {
+		return 0, errors.Errorf("expected ListHeader for array field, but got %v", format)
+	}
+	cnt := parser.ParseList(header)
+	return cnt, parser.Error()
+}
+
+/**
+	Skips the value of the unknown tag with SkipUnknownTags option, otherwise fails, used by the generated code
+*/
+
+func ParseUnknownTag(unpacker Unpacker, parser Parser, path string, tag, i int, options... UnpackOption) error {
+	ctx := newParseContext(options)
+	if !ctx.skipUnknown {
+		return errors.Errorf("unknown tag %d on position %d in '%s'", tag, i, path)
+	}
+	if _, err := doParse(unpacker, parser, ctx); err != nil {
+		return errors.Errorf("fail to parse unknown tag %d in '%s', %v", tag, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value_test
+
+//go:generate go run ./cmd/valuegen -type=GenExample -output=structgen_value_test.go
+
+import (
+	"encoding/hex"
+	"arpabet.pkg.is/value"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+	"time"
+)
+
+/**
+	@author Alex Shvid
+*/
+
+type GenLevel uint8
+
+type GenInner struct {
+
+	Name       string     `tag:"1"`
+	Values     []int16    `tag:"2"`
+
+}
+
+type GenExample struct {
+
+	Flag       bool                     `tag:"1"`
+	Small      int8                     `tag:"2"`
+	Count      int                      `tag:"3" required:"true"`
+	Big        uint64                   `tag:"4"`
+	Ratio      float32                  `tag:"5"`
+	Amount     float64                  `tag:"6"`
+	Name       string                   `tag:"7"`
+	Blob       []byte                   `tag:"8"`
+	At         time.Time                `tag:"9"`
+	Labels     map[string]string        `tag:"10"`
+	Attrs      map[string]value.Value   `tag:"11"`
+	Str        value.String             `tag:"12"`
+	Any        value.Value              `tag:"13"`
+	Inner      *GenInner                `tag:"14"`
+	Inners     []*GenInner              `tag:"15"`
+	Reps       []*GenInner              `tag:"16" repeated:"true"`
+	Nums       []value.Number           `tag:"17" repeated:"true"`
+	Level      GenLevel                 `tag:"18"`
+
+}
+
+/**
+	The same struct without generated methods, packed by reflection
+*/
+
+type ReflectExample GenExample
+
+func TestGeneratedStruct(t *testing.T) {
+
+	g := GenExample{
+		Flag: true,
+		Small: -8,
+		Count: 100,
+		Big: math.MaxUint64,
+		Ratio: 0.5,
+		Amount: 123.456,
+		Name: "name",
+		Blob: []byte{1, 2, 3},
+		At: time.Unix(1600000000, 123).UTC(),
+		Labels: map[string]string{"b": "2", "a": "1"},
+		Attrs: map[string]value.Value{"x": value.Long(1), "y": nil},
+		Str: value.Utf8("str"),
+		Any: value.SolidList([]value.Value{value.Boolean(false), value.Double(1.5)}),
+		Inner: &GenInner{"inner", []int16{-1, 1}},
+		Inners: []*GenInner{{Name: "first"}, {Name: "second"}},
+		Reps: []*GenInner{{Name: "rep"}, {Values: []int16{7}}},
+		Nums: []value.Number{value.Long(1), value.Long(2)},
+		Level: 3,
+	}
+	r := ReflectExample(g)
+
+	blob, err := value.PackStruct(&g)
+	require.Nil(t, err)
+	reflectBlob, err := value.PackStruct(&r)
+	require.Nil(t, err)
+	require.Equal(t, hex.EncodeToString(reflectBlob), hex.EncodeToString(blob))
+
+	var g2 GenExample
+	err = value.UnpackStruct(blob, &g2, false)
+	require.Nil(t, err)
+	var r2 ReflectExample
+	err = value.UnpackStruct(blob, &r2, false)
+	require.Nil(t, err)
+	require.Equal(t, GenExample(r2), g2)
+	require.Equal(t, g.Labels, g2.Labels)
+	require.True(t, g.At.Equal(g2.At))
+
+	blob, err = value.PackStruct(&GenExample{})
+	require.Nil(t, err)
+	reflectBlob, err = value.PackStruct(&ReflectExample{})
+	require.Nil(t, err)
+	require.Equal(t, hex.EncodeToString(reflectBlob), hex.EncodeToString(blob))
+
+}
+
+type GenNewer struct {
+
+	Count      int        `tag:"3"`
+	Extra      string     `tag:"99"`
+
+}
+
+type GenOlder struct {
+
+	Flag       bool       `tag:"1"`
+
+}
+
+func TestGeneratedStructErrors(t *testing.T) {
+
+	blob, err := value.PackStruct(&WideExample{Uint16Field: value.Long(1000)})
+	require.Nil(t, err)
+
+	var g GenExample
+	err = value.UnpackStruct(blob, &g, false)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "GenExample.Small")
+	require.Contains(t, err.Error(), "overflows int8")
+
+	blob, err = value.PackStruct(&GenNewer{Count: 5, Extra: "x"})
+	require.Nil(t, err)
+
+	err = value.UnpackStruct(blob, &g, false)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "unknown tag 99")
+
+	g = GenExample{}
+	err = value.UnpackStruct(blob, &g, false, value.SkipUnknownTags())
+	require.Nil(t, err)
+	require.Equal(t, 5, g.Count)
+
+	blob, err = value.PackStruct(&GenOlder{Flag: true})
+	require.Nil(t, err)
+
+	err = value.UnpackStruct(blob, &g, false)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "required field 'GenExample.Count'")
+
+}
+
+func TestGeneratedStructPayloads(t *testing.T) {
+
+	payloads := []string{
+		"81cb400800000000000005",  // {3.0: 5}
+		"82030d0dc0",              // {3: 13, 13: nil}
+		"82030d0cc0",              // {3: 13, 12: nil}
+	}
+
+	for _, payload := range payloads {
+		blob, err := hex.DecodeString(payload)
+		require.Nil(t, err)
+		for _, options := range [][]value.UnpackOption{nil, {value.WithNull()}} {
+			g := GenExample{Any: value.Long(1), Str: value.Utf8("str")}
+			r := ReflectExample(g)
+			err = value.UnpackStruct(blob, &g, false, options...)
+			require.Nil(t, err, payload)
+			err = value.UnpackStruct(blob, &r, false, options...)
+			require.Nil(t, err, payload)
+			require.Equal(t, GenExample(r), g, payload)
+		}
+	}
+
+}
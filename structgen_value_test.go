@@ -0,0 +1,423 @@
+// Code generated by valuegen; DO NOT EDIT.
+
+package value_test
+
+import (
+	"fmt"
+
+	value "arpabet.pkg.is/value"
+)
+
+// PackValue packs GenExample as MessagePack map with tag keys, the same way as value.PackStruct
+func (t *GenExample) PackValue(p value.Packer) error {
+	if t == nil {
+		p.PackNil()
+		return nil
+	}
+	cnt := 9
+	if t.Blob != nil {
+		cnt++
+	}
+	if t.Labels != nil {
+		cnt++
+	}
+	if t.Attrs != nil {
+		cnt++
+	}
+	if t.Str != nil {
+		cnt++
+	}
+	if t.Any != nil {
+		cnt++
+	}
+	if t.Inner != nil {
+		cnt++
+	}
+	if t.Inners != nil {
+		cnt++
+	}
+	cnt += len(t.Reps)
+	cnt += len(t.Nums)
+	p.PackMap(cnt)
+	p.PackLong(1)
+	value.Boolean(t.Flag).Pack(p)
+	p.PackLong(2)
+	value.Long(int64(t.Small)).Pack(p)
+	p.PackLong(3)
+	value.Long(int64(t.Count)).Pack(p)
+	p.PackLong(4)
+	value.Unsigned(t.Big).Pack(p)
+	p.PackLong(5)
+	value.Float(t.Ratio).Pack(p)
+	p.PackLong(6)
+	value.Double(t.Amount).Pack(p)
+	p.PackLong(7)
+	value.Utf8(t.Name).Pack(p)
+	if t.Blob != nil {
+		p.PackLong(8)
+		p.PackBin(t.Blob)
+	}
+	p.PackLong(9)
+	value.Timestamp(t.At).Pack(p)
+	if t.Labels != nil {
+		p.PackLong(10)
+		entries := make([]value.MapEntry, 0, len(t.Labels))
+		for k, v := range t.Labels {
+			entries = append(entries, value.Entry(k, value.Utf8(v)))
+		}
+		value.SortedMap(entries, false).Pack(p)
+	}
+	if t.Attrs != nil {
+		p.PackLong(11)
+		entries := make([]value.MapEntry, 0, len(t.Attrs))
+		for k, v := range t.Attrs {
+			entries = append(entries, value.Entry(k, v))
+		}
+		value.SortedMap(entries, false).Pack(p)
+	}
+	if t.Str != nil {
+		p.PackLong(12)
+		t.Str.Pack(p)
+	}
+	if t.Any != nil {
+		p.PackLong(13)
+		t.Any.Pack(p)
+	}
+	if t.Inner != nil {
+		p.PackLong(14)
+		if err := t.Inner.PackValue(p); err != nil {
+			return err
+		}
+	}
+	if t.Inners != nil {
+		p.PackLong(15)
+		p.PackList(len(t.Inners))
+		for _, elem := range t.Inners {
+			if err := elem.PackValue(p); err != nil {
+				return err
+			}
+		}
+	}
+	for _, elem := range t.Reps {
+		p.PackLong(16)
+		if err := elem.PackValue(p); err != nil {
+			return err
+		}
+	}
+	for _, elem := range t.Nums {
+		p.PackLong(17)
+		if elem == nil {
+			return fmt.Errorf("can not pack nil element of field Nums in %T", t)
+		}
+		elem.Pack(p)
+	}
+	p.PackLong(18)
+	value.Unsigned(uint64(t.Level)).Pack(p)
+	return nil
+}
+
+// ParseValue parses GenExample from MessagePack map with tag keys, the same way as value.UnpackStruct
+func (t *GenExample) ParseValue(unpacker value.Unpacker, parser value.Parser, options ...value.UnpackOption) error {
+	cnt, err := value.ParseStructHeader(unpacker, parser, "GenExample")
+	if err != nil {
+		return err
+	}
+	seenCount := false
+	for i := 0; i < cnt; i++ {
+		tag, err := value.ParseStructTag(unpacker, parser, "GenExample", i, options...)
+		if err != nil {
+			return err
+		}
+		switch tag {
+		case 1:
+			val, err := value.Parse(unpacker, parser, options...)
+			if err != nil {
+				return fmt.Errorf("parse field 'GenExample.Flag' on position %d, %v", i, err)
+			}
+			if x, err := value.NativeBool(val); err != nil {
+				return fmt.Errorf("fail to set value of field 'GenExample.Flag', %v", err)
+			} else {
+				t.Flag = x
+			}
+		case 2:
+			val, err := value.Parse(unpacker, parser, options...)
+			if err != nil {
+				return fmt.Errorf("parse field 'GenExample.Small' on position %d, %v", i, err)
+			}
+			if x, err := value.NativeInt(val, 8); err != nil {
+				return fmt.Errorf("fail to set value of field 'GenExample.Small', %v", err)
+			} else {
+				t.Small = int8(x)
+			}
+		case 3:
+			seenCount = true
+			val, err := value.Parse(unpacker, parser, options...)
+			if err != nil {
+				return fmt.Errorf("parse field 'GenExample.Count' on position %d, %v", i, err)
+			}
+			if x, err := value.NativeInt(val, 0); err != nil {
+				return fmt.Errorf("fail to set value of field 'GenExample.Count', %v", err)
+			} else {
+				t.Count = int(x)
+			}
+		case 4:
+			val, err := value.Parse(unpacker, parser, options...)
+			if err != nil {
+				return fmt.Errorf("parse field 'GenExample.Big' on position %d, %v", i, err)
+			}
+			if x, err := value.NativeUint(val, 64); err != nil {
+				return fmt.Errorf("fail to set value of field 'GenExample.Big', %v", err)
+			} else {
+				t.Big = x
+			}
+		case 5:
+			val, err := value.Parse(unpacker, parser, options...)
+			if err != nil {
+				return fmt.Errorf("parse field 'GenExample.Ratio' on position %d, %v", i, err)
+			}
+			if x, err := value.NativeFloat(val, 32); err != nil {
+				return fmt.Errorf("fail to set value of field 'GenExample.Ratio', %v", err)
+			} else {
+				t.Ratio = float32(x)
+			}
+		case 6:
+			val, err := value.Parse(unpacker, parser, options...)
+			if err != nil {
+				return fmt.Errorf("parse field 'GenExample.Amount' on position %d, %v", i, err)
+			}
+			if x, err := value.NativeFloat(val, 64); err != nil {
+				return fmt.Errorf("fail to set value of field 'GenExample.Amount', %v", err)
+			} else {
+				t.Amount = x
+			}
+		case 7:
+			val, err := value.Parse(unpacker, parser, options...)
+			if err != nil {
+				return fmt.Errorf("parse field 'GenExample.Name' on position %d, %v", i, err)
+			}
+			if x, err := value.NativeString(val); err != nil {
+				return fmt.Errorf("fail to set value of field 'GenExample.Name', %v", err)
+			} else {
+				t.Name = x
+			}
+		case 8:
+			val, err := value.Parse(unpacker, parser, options...)
+			if err != nil {
+				return fmt.Errorf("parse field 'GenExample.Blob' on position %d, %v", i, err)
+			}
+			if x, err := value.NativeBytes(val); err != nil {
+				return fmt.Errorf("fail to set value of field 'GenExample.Blob', %v", err)
+			} else {
+				t.Blob = x
+			}
+		case 9:
+			val, err := value.Parse(unpacker, parser, options...)
+			if err != nil {
+				return fmt.Errorf("parse field 'GenExample.At' on position %d, %v", i, err)
+			}
+			if x, err := value.NativeTime(val); err != nil {
+				return fmt.Errorf("fail to set value of field 'GenExample.At', %v", err)
+			} else {
+				t.At = x
+			}
+		case 10:
+			val, err := value.Parse(unpacker, parser, options...)
+			if err != nil {
+				return fmt.Errorf("parse field 'GenExample.Labels' on position %d, %v", i, err)
+			}
+			if value.IsNull(val) {
+				t.Labels = nil
+			} else if m, ok := val.(value.Map); !ok {
+				return fmt.Errorf("fail to set value of field 'GenExample.Labels', expected MAP for map[string]string, actual %v", val.Kind())
+			} else {
+				entries := make(map[string]string, m.Len())
+				for _, entry := range m.Entries() {
+					var elem string
+					val := entry.Value()
+					if x, err := value.NativeString(val); err != nil {
+						return fmt.Errorf("fail to set value of field 'GenExample.Labels', %v", err)
+					} else {
+						elem = x
+					}
+					entries[entry.Key()] = elem
+				}
+				t.Labels = entries
+			}
+		case 11:
+			val, err := value.Parse(unpacker, parser, options...)
+			if err != nil {
+				return fmt.Errorf("parse field 'GenExample.Attrs' on position %d, %v", i, err)
+			}
+			if value.IsNull(val) {
+				t.Attrs = nil
+			} else if m, ok := val.(value.Map); !ok {
+				return fmt.Errorf("fail to set value of field 'GenExample.Attrs', expected MAP for map[string]value.Value, actual %v", val.Kind())
+			} else {
+				entries := make(map[string]value.Value, m.Len())
+				for _, entry := range m.Entries() {
+					var elem value.Value
+					val := entry.Value()
+					if value.IsNull(val) {
+						elem = nil
+					} else {
+						elem = val
+					}
+					entries[entry.Key()] = elem
+				}
+				t.Attrs = entries
+			}
+		case 12:
+			val, err := value.Parse(unpacker, parser, options...)
+			if err != nil {
+				return fmt.Errorf("parse field 'GenExample.Str' on position %d, %v", i, err)
+			}
+			if value.IsNull(val) {
+				t.Str = nil
+			} else if x, ok := val.(value.String); ok {
+				t.Str = x
+			} else {
+				return fmt.Errorf("fail to set value of field 'GenExample.Str', expected value type value.String, actual %v", val.Class())
+			}
+		case 13:
+			val, err := value.Parse(unpacker, parser, options...)
+			if err != nil {
+				return fmt.Errorf("parse field 'GenExample.Any' on position %d, %v", i, err)
+			}
+			if value.IsNull(val) {
+				t.Any = nil
+			} else {
+				t.Any = val
+			}
+		case 14:
+			if t.Inner == nil {
+				t.Inner = new(GenInner)
+			}
+			if err := t.Inner.ParseValue(unpacker, parser, options...); err != nil {
+				return fmt.Errorf("parse field 'GenExample.Inner' on position %d, %v", i, err)
+			}
+		case 15:
+			n, err := value.ParseArrayHeader(unpacker, parser)
+			if err != nil {
+				return fmt.Errorf("parse field 'GenExample.Inners' on position %d, %v", i, err)
+			}
+			arr := make([]*GenInner, n)
+			for j := 0; j < n; j++ {
+				arr[j] = new(GenInner)
+				if err := arr[j].ParseValue(unpacker, parser, options...); err != nil {
+					return fmt.Errorf("parse field 'GenExample.Inners' on position %d, %v", i, err)
+				}
+			}
+			t.Inners = arr
+		case 16:
+			elem := new(GenInner)
+			if err := elem.ParseValue(unpacker, parser, options...); err != nil {
+				return fmt.Errorf("parse field 'GenExample.Reps' on position %d, %v", i, err)
+			}
+			t.Reps = append(t.Reps, elem)
+		case 17:
+			var elem value.Number
+			val, err := value.Parse(unpacker, parser, options...)
+			if err != nil {
+				return fmt.Errorf("parse field 'GenExample.Nums' on position %d, %v", i, err)
+			}
+			if value.IsNull(val) {
+				elem = nil
+			} else if x, ok := val.(value.Number); ok {
+				elem = x
+			} else {
+				return fmt.Errorf("fail to set value of field 'GenExample.Nums', expected value type value.Number, actual %v", val.Class())
+			}
+			t.Nums = append(t.Nums, elem)
+		case 18:
+			val, err := value.Parse(unpacker, parser, options...)
+			if err != nil {
+				return fmt.Errorf("parse field 'GenExample.Level' on position %d, %v", i, err)
+			}
+			if x, err := value.NativeUint(val, 8); err != nil {
+				return fmt.Errorf("fail to set value of field 'GenExample.Level', %v", err)
+			} else {
+				t.Level = GenLevel(x)
+			}
+		default:
+			if err := value.ParseUnknownTag(unpacker, parser, "GenExample", tag, i, options...); err != nil {
+				return err
+			}
+		}
+	}
+	if !seenCount {
+		return fmt.Errorf("required field 'GenExample.Count' with tag 3 is missing")
+	}
+	return nil
+}
+
+// PackValue packs GenInner as MessagePack map with tag keys, the same way as value.PackStruct
+func (t *GenInner) PackValue(p value.Packer) error {
+	if t == nil {
+		p.PackNil()
+		return nil
+	}
+	cnt := 1
+	if t.Values != nil {
+		cnt++
+	}
+	p.PackMap(cnt)
+	p.PackLong(1)
+	value.Utf8(t.Name).Pack(p)
+	if t.Values != nil {
+		p.PackLong(2)
+		p.PackList(len(t.Values))
+		for _, elem := range t.Values {
+			value.Long(int64(elem)).Pack(p)
+		}
+	}
+	return nil
+}
+
+// ParseValue parses GenInner from MessagePack map with tag keys, the same way as value.UnpackStruct
+func (t *GenInner) ParseValue(unpacker value.Unpacker, parser value.Parser, options ...value.UnpackOption) error {
+	cnt, err := value.ParseStructHeader(unpacker, parser, "GenInner")
+	if err != nil {
+		return err
+	}
+	for i := 0; i < cnt; i++ {
+		tag, err := value.ParseStructTag(unpacker, parser, "GenInner", i, options...)
+		if err != nil {
+			return err
+		}
+		switch tag {
+		case 1:
+			val, err := value.Parse(unpacker, parser, options...)
+			if err != nil {
+				return fmt.Errorf("parse field 'GenInner.Name' on position %d, %v", i, err)
+			}
+			if x, err := value.NativeString(val); err != nil {
+				return fmt.Errorf("fail to set value of field 'GenInner.Name', %v", err)
+			} else {
+				t.Name = x
+			}
+		case 2:
+			n, err := value.ParseArrayHeader(unpacker, parser)
+			if err != nil {
+				return fmt.Errorf("parse field 'GenInner.Values' on position %d, %v", i, err)
+			}
+			arr := make([]int16, n)
+			for j := 0; j < n; j++ {
+				val, err := value.Parse(unpacker, parser, options...)
+				if err != nil {
+					return fmt.Errorf("parse field 'GenInner.Values' on position %d, %v", i, err)
+				}
+				if x, err := value.NativeInt(val, 16); err != nil {
+					return fmt.Errorf("fail to set value of field 'GenInner.Values', %v", err)
+				} else {
+					arr[j] = int16(x)
+				}
+			}
+			t.Values = arr
+		default:
+			if err := value.ParseUnknownTag(unpacker, parser, "GenInner", tag, i, options...); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value
+
+import (
+	"github.com/pkg/errors"
+	"reflect"
+	"strconv"
+)
+
+/**
+	@author Alex Shvid
+*/
+
+type ToValueOption func(*toValueContext)
+
+type toValueContext struct {
+	tagKeys bool
+}
+
+/**
+	Keys all fields by tag number, even fields with names, like in the packed struct
+*/
+
+func WithTagKeys() ToValueOption {
+	return func(ctx *toValueContext) {
+		ctx.tagKeys = true
+	}
+}
+
+/**
+	Converts tagged struct to Value tree without serialization, good for diff, patch and query of structs
+
+	Fields are keyed by `name:"..."` if declared, otherwise by tag number, arrays become lists and inner structs become maps.
+	Nil fields are omitted, entries of the unknown catch-all field are kept by tag number.
+*/
+
+func ToValue(obj interface{}, options... ToValueOption) (Value, error) {
+	if obj == nil {
+		return nil, nil
+	}
+	if val, ok := obj.(Value); ok {
+		return val, nil
+	}
+	classPtr := reflect.TypeOf(obj)
+	if classPtr.Kind() != reflect.Ptr {
+		return nil, errors.Errorf("non-pointer instance is not allowed in '%v'", classPtr)
+	}
+	schema, err := reflectSchema(classPtr)
+	if err != nil {
+		return nil, errors.Errorf("error on reflect schema for '%v', %v", classPtr, err)
+	}
+	valuePtr := reflect.ValueOf(obj)
+	if valuePtr.IsNil() {
+		return nil, nil
+	}
+	ctx := &toValueContext{}
+	for _, opt := range options {
+		opt(ctx)
+	}
+	return structToValue(valuePtr.Elem(), schema, ctx)
+}
+
+func structToValue(value reflect.Value, schema *Schema, ctx *toValueContext) (Value, error) {
+	var entries []MapEntry
+	for _, field := range schema.SortedFields {
+		fieldValue := value.Field(field.FieldNum)
+		if isNilField(fieldValue) {
+			continue
+		}
+		var val Value
+		if field.Array {
+			n := fieldValue.Len()
+			list := make([]Value, n)
+			for i := 0; i < n; i++ {
+				elem, err := fieldToValue(fieldValue.Index(i), field, ctx)
+				if err != nil {
+					return nil, err
+				}
+				list[i] = elem
+			}
+			val = SolidList(list)
+		} else {
+			var err error
+			if val, err = fieldToValue(fieldValue, field, ctx); err != nil {
+				return nil, err
+			}
+		}
+		entries = append(entries, Entry(fieldKey(field, ctx), val))
+	}
+	if schema.Unknown != nil {
+		if unknown, ok := value.Field(schema.Unknown.FieldNum).Interface().(Map); ok && unknown != nil {
+			entries = append(entries, unknown.Entries()...)
+		}
+	}
+	return SortedMap(entries, false), nil
+}
+
+func fieldKey(field *Field, ctx *toValueContext) string {
+	if field.Name != "" && !ctx.tagKeys {
+		return field.Name
+	}
+	return strconv.Itoa(field.Tag)
+}
+
+func fieldToValue(value reflect.Value, field *Field, ctx *toValueContext) (Value, error) {
+	if field.Struct {
+		if value.IsNil() {
+			return nil, nil
+		}
+		return structToValue(value.Elem(), field.FieldSchema, ctx)
+	}
+	val, err := nativeToValue(value)
+	if err != nil {
+		return nil, errors.Errorf("can not convert field %s, %v", field.FieldName, err)
+	}
+	return val, nil
+}
+
+/**
+	Sets tagged struct from Value tree, the reverse of ToValue
+
+	Accepts maps keyed by field names or by tag numbers and lists from unpacked structs, where keys are tag numbers.
+	Array fields take lists or repeated entries, unknown tags go to the unknown catch-all field, otherwise fail.
+	Absent fields get defaults, absent required fields fail.
+*/
+
+func FromValue(v Value, objPtr interface{}) error {
+	classPtr := reflect.TypeOf(objPtr)
+	if classPtr == nil || classPtr.Kind() != reflect.Ptr {
+		return errors.Errorf("non-pointer instance is not allowed in '%v'", classPtr)
+	}
+	schema, err := reflectSchema(classPtr)
+	if err != nil {
+		return errors.Errorf("error on reflect schema for '%v', %v", classPtr, err)
+	}
+	value := reflect.ValueOf(objPtr).Elem()
+	return structFromValue(v, value, schema, value.Type().Name())
+}
+
+func structFromValue(v Value, value reflect.Value, schema *Schema, path string) error {
+	var collection Collection
+	switch {
+	case v != nil && v.Kind() == MAP:
+		collection = v.(Map)
+	case v != nil && v.Kind() == LIST:
+		collection = v.(List)
+	default:
+		return errors.Errorf("expected MAP or LIST for struct '%s', but got %v", path, v)
+	}
+	fieldValues := make(map[*Field][]Value)
+	var order []*Field
+	var unknown *MapBuilder
+	if schema.Unknown != nil {
+		unknown = NewMapBuilder(0, KeepAll)
+	}
+	for _, entry := range collection.Entries() {
+		field, ok := schema.Names[entry.Key()]
+		if !ok {
+			tag, err := strconv.Atoi(entry.Key())
+			if err != nil {
+				return errors.Errorf("unknown key '%s' in '%s'", entry.Key(), path)
+			}
+			if field, ok = schema.Fields[tag]; !ok {
+				if unknown == nil {
+					return errors.Errorf("unknown tag %d in '%s'", tag, path)
+				}
+				unknown.Put(entry.Key(), entry.Value())
+				continue
+			}
+		}
+		if _, ok := fieldValues[field]; !ok {
+			order = append(order, field)
+		}
+		fieldValues[field] = append(fieldValues[field], entry.Value())
+	}
+	seen := make(map[int]bool)
+	for _, field := range order {
+		seen[field.Tag] = true
+		fieldPath := path + "." + field.FieldName
+		if err := fieldFromValue(fieldValues[field], value.Field(field.FieldNum), field, fieldPath); err != nil {
+			return err
+		}
+	}
+	if unknown != nil {
+		unknownValue := value.Field(schema.Unknown.FieldNum)
+		if unknown.Len() > 0 {
+			unknownValue.Set(reflect.ValueOf(unknown.Freeze()))
+		} else {
+			unknownValue.Set(reflect.Zero(unknownValue.Type()))
+		}
+	}
+	return setAbsentFields(value, schema, seen, path)
+}
+
+func fieldFromValue(values []Value, fieldValue reflect.Value, field *Field, path string) error {
+	if !field.Array {
+		if len(values) > 1 {
+			return errors.Errorf("field '%s' has %d values", path, len(values))
+		}
+		return elemFromValue(values[0], fieldValue, field, path)
+	}
+	if len(values) == 1 && values[0] != nil && values[0].Kind() == LIST {
+		values = values[0].(List).Values()
+	}
+	var sliceValue reflect.Value
+	if field.FieldType.Kind() == reflect.Array {
+		if len(values) != field.FieldType.Len() {
+			return errors.Errorf("field '%s' expects %d values, actual %d", path, field.FieldType.Len(), len(values))
+		}
+		sliceValue = reflect.New(field.FieldType).Elem()
+	} else {
+		sliceValue = reflect.MakeSlice(field.FieldType, len(values), len(values))
+	}
+	for i, val := range values {
+		if err := elemFromValue(val, sliceValue.Index(i), field, path+"["+strconv.Itoa(i)+"]"); err != nil {
+			return err
+		}
+	}
+	fieldValue.Set(sliceValue)
+	return nil
+}
+
+func elemFromValue(val Value, elemValue reflect.Value, field *Field, path string) error {
+	if field.Struct {
+		if IsNull(val) {
+			elemValue.Set(reflect.Zero(elemValue.Type()))
+			return nil
+		}
+		if elemValue.IsNil() {
+			elemValue.Set(reflect.New(elemValue.Type().Elem()))
+		}
+		return structFromValue(val, elemValue.Elem(), field.FieldSchema, path)
+	}
+	if val == nil {
+		elemValue.Set(reflect.Zero(elemValue.Type()))
+		return nil
+	}
+	if err := setFieldValue(elemValue, elemValue.Type(), val); err != nil {
+		return errors.Errorf("fail to set value of field '%s', %v", path, err)
+	}
+	return nil
+}
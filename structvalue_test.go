@@ -0,0 +1,216 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value_test
+
+import (
+	val "arpabet.pkg.is/value"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type NamedInner struct {
+
+	Code       int                  `tag:"1" name:"code"`
+	Note       val.String           `tag:"2"`
+
+}
+
+type NamedExample struct {
+
+	Name       string               `tag:"1" name:"name"`
+	Count      uint16               `tag:"2" name:"count" default:"10"`
+	At         time.Time            `tag:"3" name:"at"`
+	Labels     map[string]int       `tag:"4" name:"labels"`
+	Inner      *NamedInner          `tag:"5" name:"inner"`
+	Inners     []*NamedInner        `tag:"6" name:"inners" repeated:"true"`
+	Tags       []string             `tag:"7" name:"tags"`
+	Any        val.Value            `tag:"8"`
+
+}
+
+type NamedOther struct {
+
+	Name       string               `tag:"1" name:"name" required:"true"`
+	Rest       val.Map              `unknown:"true"`
+
+}
+
+func TestStructToValue(t *testing.T) {
+
+	e := NamedExample{
+		Name: "name",
+		Count: 3,
+		At: time.Unix(1600000000, 0).UTC(),
+		Labels: map[string]int{"a": 1},
+		Inner: &NamedInner{Code: 7, Note: val.Utf8("note")},
+		Inners: []*NamedInner{{Code: 1}, {Code: 2}},
+		Tags: []string{"x", "y"},
+		Any: val.Boolean(true),
+	}
+
+	v, err := val.ToValue(&e)
+	require.Nil(t, err)
+	require.Equal(t, val.MAP, v.Kind())
+	m := v.(val.Map)
+	require.Equal(t, "name", m.GetString("name").String())
+	require.Equal(t, int64(3), m.GetNumber("count").Long())
+	require.Equal(t, int64(7), m.GetMap("inner").GetNumber("code").Long())
+	require.Equal(t, "note", m.GetMap("inner").GetString("2").String())
+	require.Equal(t, 2, m.GetList("inners").Len())
+	require.True(t, val.Boolean(true).Equal(m.GetBool("8")))
+
+	var d NamedExample
+	err = val.FromValue(v, &d)
+	require.Nil(t, err)
+	require.Equal(t, e.Name, d.Name)
+	require.True(t, e.At.Equal(d.At))
+	d.At = e.At
+	require.Equal(t, e, d)
+
+	// patch the value tree and set it back
+	v = m.Put("count", val.Long(5)).Remove("tags")
+	ops := val.Diff(m, v)
+	require.Equal(t, 2, len(ops))
+	require.Equal(t, "/count", ops[0].Pointer())
+	d = NamedExample{}
+	err = val.FromValue(v, &d)
+	require.Nil(t, err)
+	require.Equal(t, uint16(5), d.Count)
+	require.Nil(t, d.Tags)
+
+}
+
+func TestStructFromValue(t *testing.T) {
+
+	e := NamedExample{
+		Name: "name",
+		Inners: []*NamedInner{{Code: 1}, {Code: 2}},
+		Tags: []string{"x"},
+	}
+
+	// unpacked struct is a list keyed by tags with repeated entries
+	blob, err := val.PackStruct(&e)
+	require.Nil(t, err)
+	v, err := val.Unpack(blob, false)
+	require.Nil(t, err)
+	require.Equal(t, val.LIST, v.Kind())
+
+	var d NamedExample
+	err = val.FromValue(v, &d)
+	require.Nil(t, err)
+	require.Equal(t, e, d)
+
+	// absent field gets default
+	d = NamedExample{}
+	err = val.FromValue(val.EmptyMap().Put("name", val.Utf8("n")), &d)
+	require.Nil(t, err)
+	require.Equal(t, uint16(10), d.Count)
+
+	// unknown tags go to catch-all and stay in the value
+	var o NamedOther
+	err = val.FromValue(v, &o)
+	require.Nil(t, err)
+	require.Equal(t, "name", o.Name)
+	require.Equal(t, []string{"2", "3", "6", "6", "7"}, o.Rest.Keys())
+
+	ov, err := val.ToValue(&o)
+	require.Nil(t, err)
+	require.Equal(t, 6, ov.(val.Map).Len())
+
+	err = val.FromValue(val.EmptyMap().Put("unknown", val.Long(1)), &d)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "unknown key 'unknown' in 'NamedExample'")
+
+	err = val.FromValue(val.EmptyMap().Put("inner", val.EmptyMap().Put("code", val.Utf8("x"))), &d)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "NamedExample.Inner.Code")
+
+	err = val.FromValue(val.EmptyMap(), &o)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "required field 'NamedOther.Name'")
+
+}
+
+type NumericNameExample struct {
+
+	N          string     `tag:"1" name:"2"`
+	M          string     `tag:"2"`
+
+}
+
+func TestStructTagKeys(t *testing.T) {
+
+	e := NamedExample{
+		Name: "name",
+		Inner: &NamedInner{Code: 7},
+	}
+
+	v, err := val.ToValue(&e, val.WithTagKeys())
+	require.Nil(t, err)
+	m := v.(val.Map)
+	require.Equal(t, "name", m.GetString("1").String())
+	require.Equal(t, int64(7), m.GetMap("5").GetNumber("1").Long())
+	_, ok := m.Get("name")
+	require.False(t, ok)
+
+	var d NamedExample
+	err = val.FromValue(v, &d)
+	require.Nil(t, err)
+	d.At = e.At
+	require.Equal(t, e, d)
+
+	_, err = val.ToValue(&NumericNameExample{})
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "name '2' of field 'N'")
+
+	_, err = val.PackStruct(&NumericNameExample{})
+	require.NotNil(t, err)
+
+}
+
+type NamedArrayExample struct {
+
+	Codes      [2]string            `tag:"1" name:"codes"`
+	Inners     [2]*NamedInner       `tag:"2" name:"inners"`
+
+}
+
+func TestStructArrayFromValue(t *testing.T) {
+
+	e := NamedArrayExample{
+		Codes: [2]string{"a", "b"},
+		Inners: [2]*NamedInner{{Code: 1}, nil},
+	}
+
+	v, err := val.ToValue(&e)
+	require.Nil(t, err)
+	require.Equal(t, 2, v.(val.Map).GetList("codes").Len())
+
+	var d NamedArrayExample
+	err = val.FromValue(v, &d)
+	require.Nil(t, err)
+	require.Equal(t, e, d)
+
+	v = val.EmptyMap().Put("codes", val.Tuple(val.Utf8("a"), val.Utf8("b"), val.Utf8("c")))
+	err = val.FromValue(v, &d)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "expects 2 values, actual 3")
+
+}
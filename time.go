@@ -0,0 +1,154 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value
+
+import (
+	"bytes"
+	"encoding/binary"
+	"github.com/pkg/errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/**
+	Time value, serializes in MessagePack as timestamp extension in 32, 64 or 96 bit form
+
+	Prints in JSON as RFC 3339 string in UTC
+*/
+
+type timeValue time.Time
+
+var timeValueClass = reflect.TypeOf((*timeValue)(nil)).Elem()
+
+func Timestamp(t time.Time) Time {
+	return timeValue(t)
+}
+
+func Now() Time {
+	return timeValue(time.Now())
+}
+
+/**
+	Parses RFC 3339 string
+
+	return value or nil
+*/
+
+func ParseTime(str string) Time {
+	t, err := time.Parse(time.RFC3339Nano, str)
+	if err != nil {
+		return nil
+	}
+	return timeValue(t)
+}
+
+func (t timeValue) Kind() Kind {
+	return TIME
+}
+
+func (t timeValue) Class() reflect.Type {
+	return timeValueClass
+}
+
+func (t timeValue) Object() interface{} {
+	return time.Time(t)
+}
+
+func (t timeValue) Time() time.Time {
+	return time.Time(t)
+}
+
+func (t timeValue) String() string {
+	return time.Time(t).UTC().Format(time.RFC3339Nano)
+}
+
+func (t timeValue) Pack(p Packer) {
+	p.PackExt(TimeExt, packTime(time.Time(t)))
+}
+
+func (t timeValue) PrintJSON(out *strings.Builder) {
+	out.WriteRune(jsonQuote)
+	out.WriteString(t.String())
+	out.WriteRune(jsonQuote)
+}
+
+func (t timeValue) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(t.String())), nil
+}
+
+func (t timeValue) MarshalBinary() ([]byte, error) {
+	buf := bytes.Buffer{}
+	p := MessagePacker(&buf)
+	t.Pack(p)
+	return buf.Bytes(), p.Error()
+}
+
+func (t timeValue) Equal(val Value) bool {
+	if val == nil || val.Kind() != TIME {
+		return false
+	}
+	o := val.(Time)
+	return time.Time(t).Equal(o.Time())
+}
+
+func packTime(t time.Time) []byte {
+	sec := t.Unix()
+	nsec := uint32(t.Nanosecond())
+	if sec >= 0 && sec>>34 == 0 {
+		data64 := uint64(nsec)<<34 | uint64(sec)
+		if data64&0xffffffff00000000 == 0 {
+			b := make([]byte, 4)
+			binary.BigEndian.PutUint32(b, uint32(data64))
+			return b
+		}
+		b := make([]byte, 8)
+		binary.BigEndian.PutUint64(b, data64)
+		return b
+	}
+	b := make([]byte, 12)
+	binary.BigEndian.PutUint32(b, nsec)
+	binary.BigEndian.PutUint64(b[4:], uint64(sec))
+	return b
+}
+
+func UnpackTime(data []byte) (time.Time, error) {
+	switch len(data) {
+	case 4:
+		sec := binary.BigEndian.Uint32(data)
+		return time.Unix(int64(sec), 0).UTC(), nil
+	case 8:
+		data64 := binary.BigEndian.Uint64(data)
+		nsec := int64(data64 >> 34)
+		if nsec > 999999999 {
+			return time.Time{}, errors.Errorf("timestamp: invalid nanoseconds %d", nsec)
+		}
+		return time.Unix(int64(data64&0x3ffffffff), nsec).UTC(), nil
+	case 12:
+		nsec := binary.BigEndian.Uint32(data)
+		if nsec > 999999999 {
+			return time.Time{}, errors.Errorf("timestamp: invalid nanoseconds %d", nsec)
+		}
+		sec := int64(binary.BigEndian.Uint64(data[4:]))
+		return time.Unix(sec, int64(nsec)).UTC(), nil
+	default:
+		return time.Time{}, errors.Errorf("timestamp: invalid length %d", len(data))
+	}
+}
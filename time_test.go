@@ -0,0 +1,69 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value_test
+
+import (
+	val "arpabet.pkg.is/value"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+var testTimeMap = map[string]time.Time {
+
+	"d6ff5f5f5f5f": time.Unix(0x5f5f5f5f, 0),
+	"d7ff000000045f5f5f5f": time.Unix(0x5f5f5f5f, 1),
+	"c70cff00000001ffffffffffffffff": time.Unix(-1, 1),
+	"c70cff000000000000000400000000": time.Unix(1 << 34, 0),
+}
+
+func TestTime(t *testing.T) {
+
+	for hex, tm := range testTimeMap {
+
+		v := val.Timestamp(tm)
+
+		require.Equal(t, val.TIME, v.Kind())
+		require.Equal(t, "value.timeValue", v.Class().String())
+		require.Equal(t, hex, val.Hex(v))
+		require.Equal(t, "\"" + tm.UTC().Format(time.RFC3339Nano) + "\"", val.Jsonify(v))
+
+		testPackUnpack(t, v)
+
+	}
+
+}
+
+func TestTimeAccessors(t *testing.T) {
+
+	tm := time.Date(2020, 8, 1, 12, 30, 0, 500, time.UTC)
+
+	m := val.EmptyMap().Put("created", val.Timestamp(tm)).Put("updated", val.Utf8("2020-08-01T12:30:00Z"))
+	require.True(t, tm.Equal(m.GetTime("created").Time()))
+	require.True(t, tm.Truncate(time.Second).Equal(m.GetTime("updated").Time()))
+	require.Nil(t, m.GetTime("none"))
+
+	l := val.Tuple(val.Timestamp(tm), val.Long(1))
+	require.True(t, tm.Equal(l.GetTimeAt(0).Time()))
+	require.Nil(t, l.GetTimeAt(1))
+
+	s := val.SparseListOf([]val.Value{val.Timestamp(tm)})
+	require.True(t, tm.Equal(s.GetTimeAt(0).Time()))
+
+}
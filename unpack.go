@@ -29,9 +29,64 @@ import (
 	@author Alex Shvid
 */
 
-func doParse(unpacker Unpacker, parser Parser) (Value, error) {
+/**
+	Options for the Unpack, Read, ReadStream and Parse functions
+*/
+
+type UnpackOption func(*parseContext)
+
+type parseContext struct {
+	ext         *ExtRegistry
+	null        Value
+	skipUnknown bool
+}
+
+/**
+	Uses the registry to decode extensions instead of the global one
+*/
+
+func WithExtRegistry(registry *ExtRegistry) UnpackOption {
+	return func(ctx *parseContext) {
+		ctx.ext = registry
+	}
+}
+
+/**
+	Decodes MessagePack nil as Null instead of Go nil, including elements of lists and maps
+*/
+
+func WithNull() UnpackOption {
+	return func(ctx *parseContext) {
+		ctx.null = Null()
+	}
+}
 
+/**
+	Skips unknown tags in UnpackStruct instead of failing, good for the data of newer producers
+*/
+
+func SkipUnknownTags() UnpackOption {
+	return func(ctx *parseContext) {
+		ctx.skipUnknown = true
+	}
+}
+
+func newParseContext(options []UnpackOption) *parseContext {
+	ctx := &parseContext{
+		ext: defaultExtRegistry,
+	}
+	for _, opt := range options {
+		opt(ctx)
+	}
+	return ctx
+}
+
+func doParse(unpacker Unpacker, parser Parser, ctx *parseContext) (Value, error) {
 	format, header := unpacker.Next()
+	return doParseToken(format, header, unpacker, parser, ctx)
+}
+
+func doParseToken(format Format, header []byte, unpacker Unpacker, parser Parser, ctx *parseContext) (Value, error) {
 
 	switch format {
 	case EOF:
@@ -39,16 +94,20 @@ func doParse(unpacker Unpacker, parser Parser) (Value, error) {
 	case UnexpectedEOF:
 		return nil, io.ErrUnexpectedEOF
 	case NilToken:
-		return nil, nil
+		return ctx.null, nil
 	case BoolToken:
 		return Boolean(parser.ParseBool(header)), parser.Error()
 	case LongToken:
 		return Long(parser.ParseLong(header)), parser.Error()
+	case ULongToken:
+		return Unsigned(parser.ParseULong(header)), parser.Error()
 	case DoubleToken:
 		return Double(parser.ParseDouble(header)), parser.Error()
+	case FloatToken:
+		return Float(parser.ParseFloat(header)), parser.Error()
 	case FixExtToken:
 		_, tagAndData := parser.ParseExt(header)
-		return doParseExt(tagAndData)
+		return ctx.ext.Decode(tagAndData)
 	case BinHeader:
 		size := parser.ParseBin(header)
 		if parser.Error() != nil {
@@ -70,9 +129,9 @@ func doParse(unpacker Unpacker, parser Parser) (Value, error) {
 		}
 		return Utf8(string(str)), nil
 	case ListHeader:
-		return doParseList(header, unpacker, parser)
+		return doParseList(header, unpacker, parser, ctx)
 	case MapHeader:
-		return doParseMap(header, unpacker, parser)
+		return doParseMap(header, unpacker, parser, ctx)
 	case ExtHeader:
 		n, _ := parser.ParseExt(header)
 		if parser.Error() != nil {
@@ -82,14 +141,14 @@ func doParse(unpacker Unpacker, parser Parser) (Value, error) {
 		if err != nil {
 			return nil, err
 		}
-		return doParseExt(tagAndData)
+		return ctx.ext.Decode(tagAndData)
 	default:
 		return nil, errors.Errorf("parse: invalid format %v", format)
 	}
 
 }
 
-func doParseList(header []byte, unpacker Unpacker, parser Parser) (List, error) {
+func doParseList(header []byte, unpacker Unpacker, parser Parser, ctx *parseContext) (List, error) {
 	cnt := parser.ParseList(header)
 	if parser.Error() != nil {
 		return nil, parser.Error()
@@ -99,7 +158,7 @@ func doParseList(header []byte, unpacker Unpacker, parser Parser) (List, error)
 	}
 	list := make([]Value, cnt)
 	for i := 0; i < cnt; i++ {
-		el, err := doParse(unpacker, parser)
+		el, err := doParse(unpacker, parser, ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -108,7 +167,7 @@ func doParseList(header []byte, unpacker Unpacker, parser Parser) (List, error)
 	return SolidList(list), nil
 }
 
-func doParseMap(header []byte, unpacker Unpacker, parser Parser) (Value, error) {
+func doParseMap(header []byte, unpacker Unpacker, parser Parser, ctx *parseContext) (Value, error) {
 	cnt := parser.ParseMap(header)
 	if parser.Error() != nil {
 		return nil, parser.Error()
@@ -124,16 +183,16 @@ func doParseMap(header []byte, unpacker Unpacker, parser Parser) (Value, error)
 	var prevMapKey string
 
 	for i := 0; i < cnt; i++ {
-		key, err := doParse(unpacker, parser)
+		key, err := doParse(unpacker, parser, ctx)
 		if err != nil {
 			return nil, err
 		}
-		value, err := doParse(unpacker, parser)
+		value, err := doParse(unpacker, parser, ctx)
 		if err != nil {
 			return nil, err
 		}
 
-		if key == nil {
+		if IsNull(key) {
 			// nothing to do with this
 			continue
 		}
@@ -193,19 +252,3 @@ func doParseMap(header []byte, unpacker Unpacker, parser Parser) (Value, error)
 	}
 
 }
-
-func doParseExt(tagAndData []byte) (Value, error) {
-	xtag := Ext(tagAndData[0])
-	ext := tagAndData[1:]
-	switch xtag {
-
-	case BigIntExt:
-		v, err := UnpackBigInt(ext)
-		return BigInt(v), err
-	case DecimalExt:
-		v, err := UnpackDecimal(ext)
-		return Decimal(v), err
-
-	}
-	return Unknown(tagAndData), nil
-}
@@ -26,7 +26,9 @@ import (
 	"encoding/hex"
 	"errors"
 	"golang.org/x/crypto/nacl/box"
+	"hash"
 	"io"
+	"strconv"
 	"strings"
 )
 
@@ -47,16 +49,16 @@ func Pack(val Value) ([]byte, error) {
 	return buf.Bytes(), p.Error()
 }
 
-func Unpack(buf []byte, copy bool) (Value, error) {
+func Unpack(buf []byte, copy bool, options... UnpackOption) (Value, error) {
 	unpacker := MessageUnpacker(buf, copy)
 	parser := MessageParser()
-	return Parse(unpacker, parser)
+	return Parse(unpacker, parser, options...)
 }
 
-func Read(r io.Reader) (Value, error) {
+func Read(r io.Reader, options... UnpackOption) (Value, error) {
 	unpacker := MessageReader(r)
 	parser := MessageParser()
-	return Parse(unpacker, parser)
+	return Parse(unpacker, parser, options...)
 }
 
 func Write(w io.Writer, val Value) error {
@@ -80,12 +82,93 @@ func Jsonify(val Value) string {
 	return out.String()
 }
 
+/**
+	Converts value to JSON with the style of BIGINT and DECIMAL numbers, Jsonify and PrintJSON use HexNumbers
+*/
+
+func JsonifyWith(val Value, style NumberStyle) string {
+	var out strings.Builder
+	PrintJSONStyle(&out, val, style)
+	return out.String()
+}
+
+/**
+	Prints value as JSON with the style of BIGINT and DECIMAL numbers, including the elements of lists and maps
+*/
+
+func PrintJSONStyle(out *strings.Builder, val Value, style NumberStyle) {
+	if val == nil {
+		out.WriteString("null")
+		return
+	}
+	if style == HexNumbers {
+		val.PrintJSON(out)
+		return
+	}
+	switch v := val.(type) {
+	case bigIntNumber:
+		v.printJSONStyle(out, style)
+	case decimalNumber:
+		v.printJSONStyle(out, style)
+	case sparseListValue:
+		printJSONEntries(out, v.Entries(), style)
+	case List:
+		out.WriteRune('[')
+		for i, e := range v.Values() {
+			if i != 0 {
+				out.WriteRune(',')
+			}
+			PrintJSONStyle(out, e, style)
+		}
+		out.WriteRune(']')
+	case Map:
+		printJSONEntries(out, v.Entries(), style)
+	default:
+		val.PrintJSON(out)
+	}
+}
+
+func printJSONEntries(out *strings.Builder, entries []MapEntry, style NumberStyle) {
+	out.WriteRune('{')
+	for i, entry := range entries {
+		if i != 0 {
+			out.WriteRune(',')
+		}
+		out.WriteString(strconv.Quote(entry.Key()))
+		out.WriteString(": ")
+		PrintJSONStyle(out, entry.Value(), style)
+	}
+	out.WriteRune('}')
+}
+
+/**
+	Calculates digest of the packed value, streams MessagePack directly to the hasher
+ */
+
 func Hash(val Value, hash crypto.Hash) ([]byte, error) {
-	data, err := Pack(val)
-	if err != nil {
-		return nil, err
+	if !hash.Available() {
+		return nil, ErrHashUnavailable
+	}
+	return HashWith(val, hash.New())
+}
+
+var ErrHashUnavailable = errors.New("hash function is not available")
+
+/**
+	Writes packed value to the hasher and returns the digest, good for non-crypto hashes
+ */
+
+func HashWith(val Value, h hash.Hash) ([]byte, error) {
+	p := MessagePacker(h)
+	if val != nil {
+		val.Pack(p)
+	} else {
+		p.PackNil()
 	}
-	return hash.New().Sum(data), nil
+	if p.Error() != nil {
+		return nil, p.Error()
+	}
+	return h.Sum(nil), nil
 }
 
 // use box.GenerateKey(rand.Reader) to get keys
@@ -117,13 +200,13 @@ func Unseal(encrypted []byte, senderPublicKey, recipientPrivateKey *[32]byte) (V
 
 func Equal(left Value, right Value) bool {
 	if left == nil {
-		return right == nil
+		return IsNull(right)
 	}
 	return left.Equal(right)
 }
 
-func Parse(unpacker Unpacker, parser Parser) (Value, error) {
-	return doParse(unpacker, parser)
+func Parse(unpacker Unpacker, parser Parser, options... UnpackOption) (Value, error) {
+	return doParse(unpacker, parser, newParseContext(options))
 }
 
 func WriteStream(w io.Writer, valueC <-chan Value) error {
@@ -144,23 +227,22 @@ func WriteStream(w io.Writer, valueC <-chan Value) error {
 	return p.Error()
 }
 
-func ReadStream(r io.Reader, out chan<- Value) error {
+func ReadStream(r io.Reader, out chan<- Value, options... UnpackOption) error {
 
 	defer close(out)
 
 	unpacker := MessageReader(r)
 	parser := MessageParser()
+	ctx := newParseContext(options)
 
 	for {
 
-		value, err := doParse(unpacker, parser)
+		value, err := doParse(unpacker, parser, ctx)
 		if err != nil {
 			return err
 		}
 
 		out <- value
 	}
-
-	return nil
 }
 
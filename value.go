@@ -26,6 +26,7 @@ import (
 	"math/big"
 	"reflect"
 	"strings"
+	"time"
 )
 
 /**
@@ -35,6 +36,14 @@ import (
  */
 
 
+/**
+	Permits appends to the end of SolidList, SparseList and SortedMap without memory allocation,
+	the new value may share the backing array with the original one
+
+	Inserts and removals in the middle always copy, the removals at the end cap the result,
+	so the original value is never changed.
+*/
+
 var AllowFastAppends = true
 
 type Kind int
@@ -47,6 +56,8 @@ const (
 	LIST
 	MAP
 	UNKNOWN
+	TIME
+	NULL
 )
 
 func (k Kind) String() string {
@@ -65,6 +76,10 @@ func (k Kind) String() string {
 		return "MAP"
 	case UNKNOWN:
 		return "UNKNOWN"
+	case TIME:
+		return "TIME"
+	case NULL:
+		return "NULL"
 	default:
 		return "DEFAULT"
 	}
@@ -147,6 +162,8 @@ const (
 	DOUBLE
 	BIGINT
 	DECIMAL
+	ULONG
+	FLOAT
 )
 
 func (t NumberType) String() string {
@@ -161,6 +178,10 @@ func (t NumberType) String() string {
 		return "bigint"
 	case DECIMAL:
 		return "decimal"
+	case ULONG:
+		return "ulong"
+	case FLOAT:
+		return "float"
 	default:
 		return "unknown"
 	}
@@ -187,6 +208,12 @@ type Number interface {
 
 	Long() int64
 
+	/**
+		Gets number as unsigned long
+	 */
+
+	Uint64() uint64
+
 	/**
 		Gets number as double
 	 */
@@ -217,6 +244,48 @@ type Number interface {
 
 	Subtract(Number) Number
 
+	/**
+		Multiplies this number by other one and return a new one
+	 */
+
+	Multiply(Number) Number
+
+	/**
+		Divides this number by other one and return a new one, integers are divided with truncation, division by zero returns NaN
+	 */
+
+	Divide(Number) Number
+
+	/**
+		Remainder of the division with truncation, sign of the result is the sign of this number, division by zero returns NaN
+	 */
+
+	Mod(Number) Number
+
+	/**
+		Raises this number to the power of other one and return a new one
+	 */
+
+	Pow(Number) Number
+
+	/**
+		Returns the number with the opposite sign
+	 */
+
+	Negate() Number
+
+	/**
+		Returns the absolute value of the number
+	 */
+
+	Abs() Number
+
+	/**
+		Returns -1 for negative, 0 for zero and NaN, +1 for positive number
+	 */
+
+	Sign() int
+
 }
 
 /**
@@ -277,6 +346,23 @@ type String interface {
 
 }
 
+/**
+	Time interface
+
+    Packs as MessagePack timestamp extension
+
+ */
+
+type Time interface {
+	Value
+
+	/**
+		Gets payload as time
+	 */
+
+	Time() time.Time
+}
+
 type Extension interface {
 	Value
 
@@ -389,6 +475,14 @@ type List interface {
 
 	GetMapAt(int) Map
 
+	/**
+		Gets time by the index
+
+	    return value or nil
+	*/
+
+	GetTimeAt(int) Time
+
 	/**
 		Sets value to the list at position i
 	*/
@@ -508,6 +602,14 @@ type Map interface {
 
 	GetMap(string) Map
 
+	/**
+		Gets time by the key
+
+	    return value or nil
+	*/
+
+	GetTime(string) Time
+
 	/**
 		Inserts value at specific key, do not remove doubles
 	*/
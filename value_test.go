@@ -24,6 +24,9 @@ package value_test
 */
 
 import (
+	"crypto"
+	"crypto/sha256"
+	"hash/fnv"
 	"testing"
 	val "arpabet.pkg.is/value"
 	"github.com/stretchr/testify/require"
@@ -162,4 +165,42 @@ func TestPackNil(t *testing.T) {
 	require.Nil(t, actual)
 
 
-}
\ No newline at end of file
+}
+
+func TestHash(t *testing.T) {
+
+	m := testCreateMap()
+
+	mp, err := val.Pack(m)
+	require.Nil(t, err)
+	expected := sha256.Sum256(mp)
+
+	actual, err := val.Hash(m, crypto.SHA256)
+	require.Nil(t, err)
+	require.Equal(t, expected[:], actual)
+
+	h := fnv.New64a()
+	h.Write(mp)
+
+	actual, err = val.HashWith(m, fnv.New64a())
+	require.Nil(t, err)
+	require.Equal(t, h.Sum(nil), actual)
+
+	_, err = val.Hash(m, crypto.Hash(0))
+	require.Equal(t, val.ErrHashUnavailable, err)
+
+}
+
+func TestHashAllocations(t *testing.T) {
+
+	m := testCreateMap()
+	h := sha256.New()
+	p := val.MessagePacker(h)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		m.Pack(p)
+	})
+	require.Equal(t, 0.0, allocs)
+	require.Nil(t, p.Error())
+
+}
@@ -0,0 +1,585 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/**
+	This is a persistent vector List implementation for large lists
+
+	Elements are stored in the relaxed radix balanced tree, every node keeps cumulative sizes of its children,
+	so get, put, insert, remove, concatenation and slicing take O(h) of the tree height h and share untouched nodes between versions.
+	Every node has at most vectorWidth children or elements and all leaves have the same depth.
+	Nodes are not rebalanced after insert, remove, concatenation and slicing, so they may have fewer children.
+	Trees built by VectorList and Append keep nodes at least half full except the right spine, their height is O(log n).
+
+	Serializes in MessagePack exactly as solidListValue with the same elements.
+*/
+
+const vectorWidth = 32
+
+type vectorNode struct {
+	values   []Value       // elements of the leaf
+	children []*vectorNode // nil for leaf
+	sizes    []int         // cumulative sizes of children
+	height   int           // zero for leaf
+}
+
+type vectorListValue struct {
+	root *vectorNode
+}
+
+var vectorListValueClass = reflect.TypeOf((*vectorListValue)(nil)).Elem()
+
+var emptyVectorList = vectorListValue{}
+
+func EmptyVectorList() List {
+	return emptyVectorList
+}
+
+/**
+	Creates vector List with the elements, the slice is copied
+*/
+
+func VectorList(values []Value) List {
+	return vectorListValue{newVectorTree(values)}
+}
+
+/**
+	Concatenates lists in O(h) if both are vector lists, the result is always vector List
+*/
+
+func ConcatList(a, b List) List {
+	return vectorListValue{vectorJoin(toVectorTree(a), toVectorTree(b))}
+}
+
+/**
+	Gets elements [from, to) in O(h) if the list is vector list, the result is always vector List
+*/
+
+func SliceList(list List, from, to int) List {
+	root := toVectorTree(list)
+	n := root.size()
+	if from < 0 {
+		from = 0
+	}
+	if to > n {
+		to = n
+	}
+	if from >= to {
+		return emptyVectorList
+	}
+	root, _ = vectorSplit(root, to)
+	_, root = vectorSplit(root, from)
+	return vectorListValue{root}
+}
+
+func toVectorTree(list List) *vectorNode {
+	if v, ok := list.(vectorListValue); ok {
+		return v.root
+	}
+	return newVectorTree(list.Values())
+}
+
+func newVectorLeaf(values []Value) *vectorNode {
+	return &vectorNode{values: values}
+}
+
+func newVectorInternal(children []*vectorNode) *vectorNode {
+	n := &vectorNode{children: children, sizes: make([]int, len(children)), height: children[0].height + 1}
+	sum := 0
+	for i, child := range children {
+		sum += child.size()
+		n.sizes[i] = sum
+	}
+	return n
+}
+
+/**
+	Builds balanced tree bottom up, the slice is copied
+*/
+
+func newVectorTree(values []Value) *vectorNode {
+	if len(values) == 0 {
+		return nil
+	}
+	var level []*vectorNode
+	for _, r := range vectorChunks(len(values)) {
+		level = append(level, newVectorLeaf(append([]Value(nil), values[r[0]:r[1]]...)))
+	}
+	for len(level) > 1 {
+		var next []*vectorNode
+		for _, r := range vectorChunks(len(level)) {
+			next = append(next, newVectorInternal(level[r[0]:r[1]:r[1]]))
+		}
+		level = next
+	}
+	return level[0]
+}
+
+/**
+	Splits n elements to the minimum number of even chunks not larger than vectorWidth
+*/
+
+func vectorChunks(n int) [][2]int {
+	k := (n + vectorWidth - 1) / vectorWidth
+	chunks := make([][2]int, k)
+	from := 0
+	for i := 0; i < k; i++ {
+		to := from + (n-from)/(k-i)
+		chunks[i] = [2]int{from, to}
+		from = to
+	}
+	return chunks
+}
+
+func (n *vectorNode) size() int {
+	switch {
+	case n == nil:
+		return 0
+	case n.children == nil:
+		return len(n.values)
+	default:
+		return n.sizes[len(n.sizes)-1]
+	}
+}
+
+/**
+	Finds the child with the element i, returns the child index and the index in the child
+*/
+
+func (n *vectorNode) locate(i int) (int, int) {
+	c := sort.Search(len(n.sizes), func(k int) bool {
+		return n.sizes[k] > i
+	})
+	if c > 0 {
+		i -= n.sizes[c-1]
+	}
+	return c, i
+}
+
+func (n *vectorNode) get(i int) Value {
+	for n.children != nil {
+		var c int
+		c, i = n.locate(i)
+		n = n.children[c]
+	}
+	return n.values[i]
+}
+
+func (n *vectorNode) put(i int, val Value) *vectorNode {
+	if n.children == nil {
+		values := append([]Value(nil), n.values...)
+		values[i] = val
+		return newVectorLeaf(values)
+	}
+	c, j := n.locate(i)
+	children := append([]*vectorNode(nil), n.children...)
+	children[c] = children[c].put(j, val)
+	return &vectorNode{children: children, sizes: n.sizes, height: n.height}
+}
+
+func (n *vectorNode) walk(fn func(val Value)) {
+	if n == nil {
+		return
+	}
+	if n.children == nil {
+		for _, val := range n.values {
+			fn(val)
+		}
+		return
+	}
+	for _, child := range n.children {
+		child.walk(fn)
+	}
+}
+
+/**
+	Merges two nodes of the same height to one node or two even nodes
+*/
+
+func vectorMerge(a, b *vectorNode) []*vectorNode {
+	if a.children == nil {
+		values := make([]Value, 0, len(a.values)+len(b.values))
+		values = append(values, a.values...)
+		values = append(values, b.values...)
+		if len(values) <= vectorWidth {
+			return []*vectorNode{newVectorLeaf(values)}
+		}
+		m := len(values) / 2
+		return []*vectorNode{newVectorLeaf(values[:m:m]), newVectorLeaf(values[m:])}
+	}
+	children := make([]*vectorNode, 0, len(a.children)+len(b.children))
+	children = append(children, a.children...)
+	children = append(children, b.children...)
+	return vectorSplitChildren(children)
+}
+
+func vectorSplitChildren(children []*vectorNode) []*vectorNode {
+	if len(children) <= vectorWidth {
+		return []*vectorNode{newVectorInternal(children)}
+	}
+	m := len(children) / 2
+	return []*vectorNode{newVectorInternal(children[:m:m]), newVectorInternal(children[m:])}
+}
+
+/**
+	Appends lower or equal tree b to the right spine of a, returns one or two nodes of the height of a
+*/
+
+func vectorAppendTree(a, b *vectorNode) []*vectorNode {
+	if a.height == b.height {
+		return vectorMerge(a, b)
+	}
+	last := len(a.children) - 1
+	res := vectorAppendTree(a.children[last], b)
+	children := make([]*vectorNode, 0, last+len(res))
+	children = append(children, a.children[:last]...)
+	children = append(children, res...)
+	return vectorSplitChildren(children)
+}
+
+/**
+	Prepends lower tree a to the left spine of b, returns one or two nodes of the height of b
+*/
+
+func vectorPrependTree(a, b *vectorNode) []*vectorNode {
+	if a.height == b.height {
+		return vectorMerge(a, b)
+	}
+	res := vectorPrependTree(a, b.children[0])
+	children := make([]*vectorNode, 0, len(res)+len(b.children)-1)
+	children = append(children, res...)
+	children = append(children, b.children[1:]...)
+	return vectorSplitChildren(children)
+}
+
+func vectorJoin(a, b *vectorNode) *vectorNode {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	}
+	var res []*vectorNode
+	if a.height >= b.height {
+		res = vectorAppendTree(a, b)
+	} else {
+		res = vectorPrependTree(a, b)
+	}
+	if len(res) == 1 {
+		return vectorNormalize(res[0])
+	}
+	return newVectorInternal(res)
+}
+
+/**
+	Removes the root with the single child
+*/
+
+func vectorNormalize(n *vectorNode) *vectorNode {
+	for n != nil && n.children != nil && len(n.children) == 1 {
+		n = n.children[0]
+	}
+	if n.size() == 0 {
+		return nil
+	}
+	return n
+}
+
+func vectorSubtree(children []*vectorNode) *vectorNode {
+	if len(children) == 0 {
+		return nil
+	}
+	return vectorNormalize(newVectorInternal(append([]*vectorNode(nil), children...)))
+}
+
+/**
+	Splits the tree to the first i elements and the rest
+*/
+
+func vectorSplit(n *vectorNode, i int) (*vectorNode, *vectorNode) {
+	switch {
+	case n == nil:
+		return nil, nil
+	case i <= 0:
+		return nil, n
+	case i >= n.size():
+		return n, nil
+	case n.children == nil:
+		return newVectorLeaf(append([]Value(nil), n.values[:i]...)), newVectorLeaf(append([]Value(nil), n.values[i:]...))
+	}
+	c, j := n.locate(i)
+	if j == 0 {
+		return vectorSubtree(n.children[:c]), vectorSubtree(n.children[c:])
+	}
+	l, r := vectorSplit(n.children[c], j)
+	return vectorJoin(vectorSubtree(n.children[:c]), l), vectorJoin(r, vectorSubtree(n.children[c+1:]))
+}
+
+func (t vectorListValue) Kind() Kind {
+	return LIST
+}
+
+func (t vectorListValue) Class() reflect.Type {
+	return vectorListValueClass
+}
+
+func (t vectorListValue) Object() interface{} {
+	return t.Values()
+}
+
+func (t vectorListValue) String() string {
+	var out strings.Builder
+	t.PrintJSON(&out)
+	return out.String()
+}
+
+func (t vectorListValue) Items() []ListItem {
+	var items []ListItem
+	key := 0
+	t.root.walk(func(value Value) {
+		items = append(items, Item(key, value))
+		key++
+	})
+	return items
+}
+
+func (t vectorListValue) Entries() []MapEntry {
+	var entries []MapEntry
+	key := 0
+	t.root.walk(func(value Value) {
+		entries = append(entries, Entry(strconv.Itoa(key), value))
+		key++
+	})
+	return entries
+}
+
+func (t vectorListValue) Values() []Value {
+	values := make([]Value, 0, t.root.size())
+	t.root.walk(func(value Value) {
+		values = append(values, value)
+	})
+	return values
+}
+
+func (t vectorListValue) Len() int {
+	return t.root.size()
+}
+
+func (t vectorListValue) Pack(p Packer) {
+
+	p.PackList(t.root.size())
+
+	t.root.walk(func(e Value) {
+		if e != nil {
+			e.Pack(p)
+		} else {
+			p.PackNil()
+		}
+	})
+}
+
+func (t vectorListValue) PrintJSON(out *strings.Builder) {
+	out.WriteRune('[')
+	first := true
+	t.root.walk(func(e Value) {
+		if !first {
+			out.WriteRune(',')
+		}
+		first = false
+		if e != nil {
+			e.PrintJSON(out)
+		} else {
+			out.WriteString("null")
+		}
+	})
+	out.WriteRune(']')
+}
+
+func (t vectorListValue) MarshalJSON() ([]byte, error) {
+	var out strings.Builder
+	t.PrintJSON(&out)
+	return []byte(out.String()), nil
+}
+
+func (t vectorListValue) MarshalBinary() ([]byte, error) {
+	buf := bytes.Buffer{}
+	p := MessagePacker(&buf)
+	t.Pack(p)
+	return buf.Bytes(), p.Error()
+}
+
+func (t vectorListValue) Equal(val Value) bool {
+	if val == nil || val.Kind() != LIST {
+		return false
+	}
+	o := val.(List)
+	if t.Len() != o.Len() {
+		return false
+	}
+	other := o.Values()
+	i := 0
+	equal := true
+	t.root.walk(func(item Value) {
+		if equal && !Equal(item, other[i]) {
+			equal = false
+		}
+		i++
+	})
+	return equal
+}
+
+func (t vectorListValue) GetAt(i int) Value {
+	if i >= 0 && i < t.root.size() {
+		return t.root.get(i)
+	}
+	return nil
+}
+
+func (t vectorListValue) GetBoolAt(index int) Bool {
+	value := t.GetAt(index)
+	if !IsNull(value) {
+		if value.Kind() == BOOL {
+			return value.(Bool)
+		}
+		return ParseBoolean(value.String())
+	}
+	return nil
+}
+
+func (t vectorListValue) GetNumberAt(index int) Number {
+	value := t.GetAt(index)
+	if !IsNull(value) {
+		if value.Kind() == NUMBER {
+			return value.(Number)
+		}
+		return ParseNumber(value.String())
+	}
+	return nil
+}
+
+func (t vectorListValue) GetStringAt(index int) String {
+	value := t.GetAt(index)
+	if !IsNull(value) {
+		if value.Kind() == STRING {
+			return value.(String)
+		}
+		return ParseString(value.String())
+	}
+	return nil
+}
+
+func (t vectorListValue) GetListAt(index int) List {
+	value := t.GetAt(index)
+	if !IsNull(value) {
+		switch value.Kind() {
+		case LIST:
+			return value.(List)
+		case MAP:
+			return SolidList(value.(Map).Values())
+		}
+	}
+	return nil
+}
+
+func (t vectorListValue) GetMapAt(index int) Map {
+	value := t.GetAt(index)
+	if !IsNull(value) {
+		switch value.Kind() {
+		case LIST:
+			return SortedMap(value.(List).Entries(), false)
+		case MAP:
+			return value.(Map)
+		}
+	}
+	return nil
+}
+
+func (t vectorListValue) GetTimeAt(index int) Time {
+	value := t.GetAt(index)
+	if !IsNull(value) {
+		if value.Kind() == TIME {
+			return value.(Time)
+		}
+		return ParseTime(value.String())
+	}
+	return nil
+}
+
+func (t vectorListValue) Append(val Value) List {
+	return vectorListValue{vectorJoin(t.root, newVectorLeaf([]Value{val}))}
+}
+
+/**
+	Sets value at position i, extends the list by nils if i is out of bounds like solidListValue
+*/
+
+func (t vectorListValue) PutAt(i int, val Value) List {
+	n := t.root.size()
+	switch {
+	case i < 0:
+		return t
+	case i < n:
+		return vectorListValue{t.root.put(i, val)}
+	default:
+		values := make([]Value, i-n+1)
+		values[i-n] = val
+		return vectorListValue{vectorJoin(t.root, newVectorTree(values))}
+	}
+}
+
+func (t vectorListValue) InsertAt(i int, val Value) List {
+	return t.InsertAll(i, []Value{val})
+}
+
+func (t vectorListValue) RemoveAt(i int) List {
+	if i < 0 || i >= t.root.size() {
+		return t
+	}
+	left, rest := vectorSplit(t.root, i)
+	_, right := vectorSplit(rest, 1)
+	return vectorListValue{vectorJoin(left, right)}
+}
+
+func (t vectorListValue) Select(i int) []Value {
+	val := t.GetAt(i)
+	if val != nil {
+		return []Value{val}
+	}
+	return nil
+}
+
+func (t vectorListValue) InsertAll(i int, list []Value) List {
+	if i < 0 || len(list) == 0 {
+		return t
+	}
+	left, right := vectorSplit(t.root, i)
+	return vectorListValue{vectorJoin(vectorJoin(left, newVectorTree(list)), right)}
+}
+
+func (t vectorListValue) DeleteAll(i int) List {
+	return t.RemoveAt(i)
+}
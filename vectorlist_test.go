@@ -0,0 +1,164 @@
+/*
+ *
+ * Copyright 2020-present Arpabet, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package value_test
+
+import (
+	val "arpabet.pkg.is/value"
+	"github.com/stretchr/testify/require"
+	"math/rand"
+	"testing"
+)
+
+
+func TestVectorList(t *testing.T) {
+
+	b := val.EmptyVectorList()
+	require.Equal(t, val.LIST, b.Kind())
+	require.Equal(t, "value.vectorListValue", b.Class().String())
+	require.Equal(t, 0, b.Len())
+	require.Equal(t, "[]", b.String())
+	require.Equal(t, "90", val.Hex(b))
+
+	b = b.Append(val.Long(1)).Append(val.Utf8("a")).Append(nil)
+	require.Equal(t, 3, b.Len())
+	require.Equal(t, "[1,\"a\",null]", b.String())
+
+	s := val.Tuple(val.Long(1), val.Utf8("a"), nil)
+	require.True(t, s.Equal(b))
+	require.True(t, b.Equal(s))
+	require.Equal(t, val.Hex(s), val.Hex(b))
+	require.Equal(t, 0, val.Compare(s, b))
+
+	require.Equal(t, int64(1), b.GetNumberAt(0).Long())
+	require.Equal(t, "a", b.GetStringAt(1).String())
+	require.Nil(t, b.GetAt(2))
+	require.Nil(t, b.GetAt(3))
+	require.Nil(t, b.Select(2))
+
+	b = b.PutAt(5, val.Long(5))
+	s = s.PutAt(5, val.Long(5))
+	require.Equal(t, 6, b.Len())
+	require.Equal(t, val.Hex(s), val.Hex(b))
+
+	require.Equal(t, s.Items(), b.Items())
+	require.Equal(t, s.Entries(), b.Entries())
+
+}
+
+func TestVectorListRandom(t *testing.T) {
+
+	rnd := rand.New(rand.NewSource(1))
+
+	var s val.List = val.EmptyList()
+	b := val.EmptyVectorList()
+
+	var versions []val.List
+	var expected []string
+
+	for i := 0; i < 5000; i++ {
+		v := val.Long(int64(i))
+		n := s.Len()
+		switch rnd.Intn(6) {
+		case 0:
+			s, b = s.Append(v), b.Append(v)
+		case 1:
+			j := rnd.Intn(n + 1)
+			s, b = s.InsertAt(j, v), b.InsertAt(j, v)
+		case 2:
+			j := rnd.Intn(n + 1)
+			list := []val.Value{v, val.Utf8("x"), v}
+			s, b = s.InsertAll(j, list), b.InsertAll(j, list)
+		case 3:
+			j := rnd.Intn(n + 1)
+			s, b = s.PutAt(j, v), b.PutAt(j, v)
+		default:
+			if n > 0 {
+				j := rnd.Intn(n)
+				s, b = s.RemoveAt(j), b.RemoveAt(j)
+			}
+		}
+		require.Equal(t, s.Len(), b.Len())
+		if i%500 == 0 {
+			versions = append(versions, b)
+			expected = append(expected, val.Hex(s))
+		}
+	}
+
+	require.Equal(t, val.Hex(s), val.Hex(b))
+	require.True(t, s.Equal(b))
+	for i := 0; i < s.Len(); i++ {
+		require.True(t, val.Equal(s.GetAt(i), b.GetAt(i)))
+	}
+
+	for i, v := range versions {
+		require.Equal(t, expected[i], val.Hex(v))
+	}
+
+}
+
+func TestConcatSliceList(t *testing.T) {
+
+	values := make([]val.Value, 100000)
+	for i := range values {
+		values[i] = val.Long(int64(i))
+	}
+
+	s := val.SolidList(values)
+	b := val.VectorList(values)
+
+	mp, err := val.Pack(b)
+	require.NoError(t, err)
+	expected, err := val.Pack(s)
+	require.NoError(t, err)
+	require.Equal(t, expected, mp)
+	require.Equal(t, []byte{0xdd, 0, 0x01, 0x86, 0xa0}, mp[:5])
+
+	c, err := val.Unpack(mp, false)
+	require.NoError(t, err)
+	require.Equal(t, 100000, c.(val.List).Len())
+
+	for _, r := range [][2]int{{0, 0}, {0, 1}, {31, 33}, {1000, 70000}, {99999, 100000}, {-5, 200000}} {
+		slice := val.SliceList(b, r[0], r[1])
+		from, to := r[0], r[1]
+		if from < 0 {
+			from = 0
+		}
+		if to > len(values) {
+			to = len(values)
+		}
+		require.Equal(t, to-from, slice.Len())
+		require.True(t, val.SolidList(values[from:to]).Equal(slice))
+	}
+
+	for _, i := range []int{0, 1, 32, 1024, 50001, 100000} {
+		joined := val.ConcatList(val.SliceList(b, 0, i), val.SliceList(b, i, len(values)))
+		require.True(t, b.Equal(joined))
+	}
+
+	small := val.Tuple(val.Long(-1), val.Long(-2))
+	joined := val.ConcatList(small, b)
+	require.Equal(t, len(values)+2, joined.Len())
+	require.Equal(t, int64(-2), joined.GetNumberAt(1).Long())
+	require.Equal(t, int64(0), joined.GetNumberAt(2).Long())
+
+	joined = val.ConcatList(b, small)
+	require.Equal(t, int64(99999), joined.GetNumberAt(99999).Long())
+	require.Equal(t, int64(-1), joined.GetNumberAt(100000).Long())
+
+}